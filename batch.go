@@ -0,0 +1,368 @@
+package structscanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RecordError is returned by DecodeSlice and DecodeEach when they fail
+// to decode one of the records, Index is the position of its source.
+type RecordError struct {
+	Index int
+	Err   error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("error decoding record %d: %s", e.Index, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// RecordErrors is used for reporting the errors of several records
+// at once when the `CollectRecordErrors()` option is used.
+type RecordErrors []*RecordError
+
+func (e RecordErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the record errors, which on Go 1.20 or newer
+// allows errors.Is and errors.As to match any of them.
+func (e RecordErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Is allows errors.Is to match any of the record errors
+// on versions of Go that ignore the Unwrap() []error method.
+func (e RecordErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As allows errors.As to match any of the record errors
+// on versions of Go that ignore the Unwrap() []error method.
+func (e RecordErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectRecordErrors makes DecodeSlice and DecodeEach keep decoding
+// the remaining records when one of them fails, the errors of all the
+// failed records are then returned together as RecordErrors.
+//
+// By default they stop on the first record that fails.
+func CollectRecordErrors() Option {
+	return func(o *options) {
+		o.collectRecordErrors = true
+	}
+}
+
+// WithWorkers makes DecodeSlice decode up to n records concurrently,
+// which speeds up large batches since decoding is mostly CPU-bound.
+//
+// The output keeps the order of the sources and the errors are the same
+// ones of a sequential decoding, i.e. the record with the lowest index
+// is reported first. Each record is decoded on a single goroutine, from
+// the call to the factory to the last call to its decoder, so the
+// decoders don't need to be safe for concurrent use, but the factory
+// function itself is called concurrently.
+//
+// Panics on the workers, including the ones of the factory function,
+// are returned as a RecordError containing a PanicError, or if the
+// `DisablePanicRecovery()` option is used they are raised again on
+// the caller's goroutine after all the workers stop.
+//
+// The report of the `WithReport()` option is not filled when n > 1.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// DecodeSlice decodes each of the sources into a struct of type T using
+// the decoder returned by the factory function for that source, e.g.:
+//
+//	users, err := structscanner.DecodeSlice[User](maps, func(m map[string]interface{}) structscanner.TagDecoder {
+//		return structscanner.NewMapTagDecoder("map", m)
+//	})
+//
+// The struct info of T is computed only once for the whole batch, and
+// the errors are returned as a RecordError with the index of the source
+// that failed. With the `CollectRecordErrors()` option the records that
+// fail are left with their zero values and all the decoded records are
+// returned together with the errors.
+//
+// Use the `WithWorkers()` option for decoding the records concurrently
+// and the `WithScanner()` option for using the configuration of a Scanner.
+// The other options are applied to each of the records, notice that
+// the report of the `WithReport()` option only describes the last one.
+func DecodeSlice[T any, S any](sources []S, factory func(S) TagDecoder, opts ...Option) ([]T, error) {
+	o := parseOptions(opts)
+	err := checkRecordType[T](o.cache)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]T, len(sources))
+	errs := decodeRecords(o, len(sources), func(o options, i int) *RecordError {
+		return decodeRecord(o, i, &records[i], factory(sources[i]))
+	})
+	if len(errs) > 0 {
+		if !o.collectRecordErrors {
+			return nil, errs[0]
+		}
+
+		var zero T
+		for _, err := range errs {
+			records[err.Index] = zero
+		}
+		return records, errs
+	}
+
+	return records, nil
+}
+
+// decodeRecords calls decode for the indexes from 0 to n-1 using the
+// number of workers set by the `WithWorkers()` option, and returns the
+// errors sorted by index, or only the first one if the errors are not
+// being collected.
+func decodeRecords(o options, n int, decode func(o options, i int) *RecordError) RecordErrors {
+	workers := o.workers
+	if workers > n {
+		workers = n
+	}
+
+	if workers <= 1 {
+		var errs RecordErrors
+		for i := 0; i < n; i++ {
+			err := decodeRecordOnCaller(o, i, decode)
+			if err != nil {
+				errs = append(errs, err)
+				if !o.collectRecordErrors {
+					break
+				}
+			}
+		}
+		return errs
+	}
+
+	// The report can't be shared by concurrent decodings:
+	o.report = nil
+
+	var mutex sync.Mutex
+	var errs RecordErrors
+	var lowestPanic *recordPanic
+	lowestFailure := n
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// Records after a failure are skipped since they are not
+				// returned, but the ones before it must still be decoded
+				// because one of them might fail with a lower index:
+				mutex.Lock()
+				skip := !o.collectRecordErrors && i > lowestFailure
+				mutex.Unlock()
+				if skip {
+					continue
+				}
+
+				err, p := decodeRecordSafely(o, i, decode)
+				if err == nil && p == nil {
+					continue
+				}
+
+				mutex.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				}
+				if p != nil && (lowestPanic == nil || p.index < lowestPanic.index) {
+					lowestPanic = p
+				}
+				if i < lowestFailure {
+					lowestFailure = i
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	// Panics on the workers would crash the program, so
+	// they are raised again on the caller's goroutine:
+	if lowestPanic != nil {
+		panic(lowestPanic.value)
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Index < errs[j].Index
+	})
+	if !o.collectRecordErrors && len(errs) > 1 {
+		errs = errs[:1]
+	}
+
+	return errs
+}
+
+// DecodeEach works like DecodeSlice but for streaming the records
+// instead of loading all of them into memory, it decodes each
+// source returned by the next function and passes the
+// decoded record to fn until next returns false, e.g.:
+//
+//	err := structscanner.DecodeEach(
+//		func() (*sql.Rows, bool) { return rows, rows.Next() },
+//		func(rows *sql.Rows) structscanner.TagDecoder { return decoderForRow(rows) },
+//		func(i int, user User) error {
+//			return process(user)
+//		},
+//	)
+//
+// If fn returns an error DecodeEach stops and returns it unchanged. With
+// the `CollectRecordErrors()` option fn is not called for the records that
+// fail and their errors are returned together after the last record.
+func DecodeEach[T any, S any](
+	next func() (S, bool),
+	factory func(S) TagDecoder,
+	fn func(index int, record T) error,
+	opts ...Option,
+) error {
+	o := parseOptions(opts)
+	err := checkRecordType[T](o.cache)
+	if err != nil {
+		return err
+	}
+
+	var errs RecordErrors
+	for i := 0; ; i++ {
+		source, ok := next()
+		if !ok {
+			break
+		}
+
+		var record T
+		recordErr := decodeRecordOnCaller(o, i, func(o options, i int) *RecordError {
+			return decodeRecord(o, i, &record, factory(source))
+		})
+		if recordErr != nil {
+			if !o.collectRecordErrors {
+				return recordErr
+			}
+
+			errs = append(errs, recordErr)
+			continue
+		}
+
+		err := fn(i, record)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// recordPanic holds a panic recovered from a
+// worker when panic recovery is disabled.
+type recordPanic struct {
+	index int
+	value interface{}
+}
+
+// decodeRecordSafely calls decode recovering the panics, including the ones
+// of the factory function, which are returned as a RecordError containing
+// a PanicError, or as a recordPanic if panic recovery is disabled.
+func decodeRecordSafely(
+	o options,
+	i int,
+	decode func(o options, i int) *RecordError,
+) (err *RecordError, p *recordPanic) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		if o.disablePanicRecovery {
+			p = &recordPanic{index: i, value: r}
+			return
+		}
+
+		err = &RecordError{
+			Index: i,
+			Err: &PanicError{
+				Value: r,
+				Stack: debug.Stack(),
+			},
+		}
+	}()
+
+	return decode(o, i), nil
+}
+
+// decodeRecordOnCaller works like decodeRecordSafely but for records decoded
+// on the caller's goroutine, where the panics can just propagate when
+// the recovery is disabled.
+func decodeRecordOnCaller(o options, i int, decode func(o options, i int) *RecordError) *RecordError {
+	if o.disablePanicRecovery {
+		return decode(o, i)
+	}
+
+	err, _ := decodeRecordSafely(o, i, decode)
+	return err
+}
+
+// checkRecordType fails early if T is not a struct and stores
+// its struct info on the cache for the records of the batch.
+func checkRecordType[T any](cache *Cache) error {
+	_, _, err := cache.getStructInfoForType(reflect.TypeOf((*T)(nil)))
+	return err
+}
+
+func decodeRecord(o options, index int, target interface{}, decoder TagDecoder) *RecordError {
+	err := newDecodeState(context.Background(), o).decode(target, decoder)
+	if err != nil {
+		return &RecordError{
+			Index: index,
+			Err:   err,
+		}
+	}
+
+	return nil
+}
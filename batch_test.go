@@ -0,0 +1,322 @@
+package structscanner_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+type batchUser struct {
+	Name string `map:"name" validate:"min=1"`
+	Age  int    `map:"age"`
+}
+
+func newBatchDecoder(m map[string]interface{}) ss.TagDecoder {
+	return ss.NewMapTagDecoder("map", m)
+}
+
+func TestDecodeSlice(t *testing.T) {
+	t.Run("should decode all the sources", func(t *testing.T) {
+		users, err := ss.DecodeSlice[batchUser]([]map[string]interface{}{
+			{"name": "fakeName1", "age": 21},
+			{"name": "fakeName2", "age": 42},
+		}, newBatchDecoder)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, users, []batchUser{
+			{Name: "fakeName1", Age: 21},
+			{Name: "fakeName2", Age: 42},
+		})
+	})
+
+	t.Run("should return an empty slice for no sources", func(t *testing.T) {
+		users, err := ss.DecodeSlice[batchUser]([]map[string]interface{}{}, newBatchDecoder)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, users, []batchUser{})
+	})
+
+	t.Run("should stop on the first error with the index of the record", func(t *testing.T) {
+		calls := 0
+		users, err := ss.DecodeSlice[batchUser]([]map[string]interface{}{
+			{"name": "fakeName1"},
+			{"age": "notAnInt"},
+			{"age": 42},
+		}, func(m map[string]interface{}) ss.TagDecoder {
+			calls++
+			return ss.NewMapTagDecoder("map", m)
+		})
+		tt.AssertErrContains(t, err, "error decoding record 1", "Age")
+		tt.AssertEqual(t, calls, 2)
+		tt.AssertEqual(t, users, []batchUser(nil))
+
+		var recordErr *ss.RecordError
+		tt.AssertTrue(t, errors.As(err, &recordErr), "error %#v should be a RecordError", err)
+		tt.AssertEqual(t, recordErr.Index, 1)
+	})
+
+	t.Run("should collect the errors of all records", func(t *testing.T) {
+		users, err := ss.DecodeSlice[batchUser]([]map[string]interface{}{
+			{"name": "fakeName1"},
+			{"age": "notAnInt"},
+			{"name": "fakeName3"},
+			{"age": 42},
+		}, newBatchDecoder, ss.CollectRecordErrors())
+		tt.AssertErrContains(t, err, "error decoding record 1", "error decoding record 3", "Name")
+		tt.AssertEqual(t, users, []batchUser{
+			{Name: "fakeName1"},
+			{},
+			{Name: "fakeName3"},
+			{},
+		})
+
+		var recordErrs ss.RecordErrors
+		tt.AssertTrue(t, errors.As(err, &recordErrs), "error %#v should be a RecordErrors", err)
+		tt.AssertEqual(t, len(recordErrs), 2)
+		tt.AssertEqual(t, recordErrs[0].Index, 1)
+		tt.AssertEqual(t, recordErrs[1].Index, 3)
+
+		// Is and As work even on Go versions that ignore Unwrap() []error:
+		var recordErr *ss.RecordError
+		tt.AssertTrue(t, recordErrs.As(&recordErr), "RecordErrors should match a RecordError")
+		tt.AssertEqual(t, recordErr.Index, 1)
+		tt.AssertTrue(t, recordErrs.Is(recordErrs[1]), "RecordErrors should match any of its errors")
+	})
+
+	t.Run("should fail early if the target is not a struct", func(t *testing.T) {
+		_, err := ss.DecodeSlice[int]([]map[string]interface{}{}, newBatchDecoder)
+		tt.AssertErrContains(t, err, "can only get struct info from structs", "int")
+	})
+
+	t.Run("should use the configuration of the WithScanner option", func(t *testing.T) {
+		type User struct {
+			FullName string `validate:"capitalized"`
+		}
+
+		scanner := ss.New(ss.WithNaming(ss.SnakeCase), ss.Strict())
+		scanner.RegisterValidation("capitalized", func(value reflect.Value, param string) error {
+			if value.String() == "" || value.String()[0] < 'A' || value.String()[0] > 'Z' {
+				return errors.New("must be capitalized")
+			}
+			return nil
+		})
+
+		users, err := ss.DecodeSlice[User]([]map[string]interface{}{
+			{"full_name": "FakeName1"},
+			{"full_name": "FakeName2"},
+		}, newBatchDecoder, ss.WithScanner(scanner))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, users, []User{{FullName: "FakeName1"}, {FullName: "FakeName2"}})
+		tt.AssertEqual(t, scanner.Cache().Stats().Entries, 1)
+
+		_, err = ss.DecodeSlice[User]([]map[string]interface{}{
+			{"full_name": "fakeName1", "fullname": "fakeName1"},
+		}, newBatchDecoder, ss.WithScanner(scanner), ss.WithWorkers(2))
+		tt.AssertErrContains(t, err,
+			"record 0",
+			"FullName: must be capitalized",
+			`unknown key "fullname"`,
+		)
+	})
+}
+
+func TestDecodeEach(t *testing.T) {
+	sliceIterator := func(sources []map[string]interface{}) func() (map[string]interface{}, bool) {
+		return func() (map[string]interface{}, bool) {
+			if len(sources) == 0 {
+				return nil, false
+			}
+			source := sources[0]
+			sources = sources[1:]
+			return source, true
+		}
+	}
+
+	t.Run("should stream the decoded records", func(t *testing.T) {
+		var indexes []int
+		var users []batchUser
+		err := ss.DecodeEach(sliceIterator([]map[string]interface{}{
+			{"name": "fakeName1", "age": 21},
+			{"name": "fakeName2", "age": 42},
+		}), newBatchDecoder, func(i int, user batchUser) error {
+			indexes = append(indexes, i)
+			users = append(users, user)
+			return nil
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, indexes, []int{0, 1})
+		tt.AssertEqual(t, users, []batchUser{
+			{Name: "fakeName1", Age: 21},
+			{Name: "fakeName2", Age: 42},
+		})
+	})
+
+	t.Run("should stop on the first error with the index of the record", func(t *testing.T) {
+		var users []batchUser
+		err := ss.DecodeEach(sliceIterator([]map[string]interface{}{
+			{"name": "fakeName1"},
+			{"age": 42},
+			{"name": "fakeName3"},
+		}), newBatchDecoder, func(i int, user batchUser) error {
+			users = append(users, user)
+			return nil
+		})
+		tt.AssertErrContains(t, err, "error decoding record 1", "Name")
+		tt.AssertEqual(t, users, []batchUser{{Name: "fakeName1"}})
+	})
+
+	t.Run("should skip and collect the errors of all records", func(t *testing.T) {
+		var users []batchUser
+		err := ss.DecodeEach(sliceIterator([]map[string]interface{}{
+			{"age": 21},
+			{"name": "fakeName2"},
+			{"age": 42},
+		}), newBatchDecoder, func(i int, user batchUser) error {
+			users = append(users, user)
+			return nil
+		}, ss.CollectRecordErrors())
+		tt.AssertErrContains(t, err, "error decoding record 0", "error decoding record 2")
+		tt.AssertEqual(t, users, []batchUser{{Name: "fakeName2"}})
+	})
+
+	t.Run("should stop with the error returned by the callback", func(t *testing.T) {
+		fakeErr := errors.New("fakeErr")
+		calls := 0
+		err := ss.DecodeEach(sliceIterator([]map[string]interface{}{
+			{"name": "fakeName1"},
+			{"name": "fakeName2"},
+		}), newBatchDecoder, func(i int, user batchUser) error {
+			calls++
+			return fakeErr
+		})
+		tt.AssertEqual(t, err, fakeErr)
+		tt.AssertEqual(t, calls, 1)
+	})
+}
+
+// countingDecoder is not safe for concurrent use on purpose,
+// so the race detector fails if it is shared by goroutines.
+type countingDecoder struct {
+	source map[string]interface{}
+	calls  *int
+}
+
+func (c countingDecoder) DecodeField(field ss.Field) (interface{}, error) {
+	*c.calls++
+	return c.source[field.Key("map")], nil
+}
+
+func TestDecodeSliceWithWorkers(t *testing.T) {
+	newSources := func(n int) []map[string]interface{} {
+		sources := make([]map[string]interface{}, n)
+		for i := range sources {
+			sources[i] = map[string]interface{}{
+				"name": "fakeName",
+				"age":  i,
+			}
+		}
+		return sources
+	}
+
+	t.Run("should preserve the order of the sources", func(t *testing.T) {
+		calls := make([]int, 1000)
+		sources := newSources(len(calls))
+		for i := range sources {
+			sources[i]["index"] = i
+		}
+
+		users, err := ss.DecodeSlice[batchUser](sources, func(m map[string]interface{}) ss.TagDecoder {
+			return countingDecoder{
+				source: m,
+				calls:  &calls[m["index"].(int)],
+			}
+		}, ss.WithWorkers(8))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(users), len(sources))
+		for i, user := range users {
+			tt.AssertEqual(t, user, batchUser{Name: "fakeName", Age: i})
+			tt.AssertEqual(t, calls[i], 2)
+		}
+	})
+
+	t.Run("should report the error with the lowest index", func(t *testing.T) {
+		sources := newSources(1000)
+		sources[700]["age"] = "notAnInt"
+		sources[300]["age"] = "notAnInt"
+		sources[10]["name"] = ""
+
+		for i := 0; i < 10; i++ {
+			users, err := ss.DecodeSlice[batchUser](sources, newBatchDecoder, ss.WithWorkers(8))
+			tt.AssertErrContains(t, err, "error decoding record 10:", "Name")
+			tt.AssertEqual(t, users, []batchUser(nil))
+
+			var recordErr *ss.RecordError
+			tt.AssertTrue(t, errors.As(err, &recordErr), "error %#v should be a RecordError", err)
+			tt.AssertEqual(t, recordErr.Index, 10)
+		}
+	})
+
+	t.Run("should collect the errors sorted by index", func(t *testing.T) {
+		sources := newSources(1000)
+		sources[700]["age"] = "notAnInt"
+		sources[300]["age"] = "notAnInt"
+		sources[10]["name"] = ""
+
+		users, err := ss.DecodeSlice[batchUser](sources, newBatchDecoder, ss.WithWorkers(8), ss.CollectRecordErrors())
+
+		var recordErrs ss.RecordErrors
+		tt.AssertTrue(t, errors.As(err, &recordErrs), "error %#v should be a RecordErrors", err)
+		tt.AssertEqual(t, len(recordErrs), 3)
+		tt.AssertEqual(t, recordErrs[0].Index, 10)
+		tt.AssertEqual(t, recordErrs[1].Index, 300)
+		tt.AssertEqual(t, recordErrs[2].Index, 700)
+
+		tt.AssertEqual(t, len(users), len(sources))
+		tt.AssertEqual(t, users[10], batchUser{})
+		tt.AssertEqual(t, users[300], batchUser{})
+		tt.AssertEqual(t, users[301], batchUser{Name: "fakeName", Age: 301})
+	})
+
+	t.Run("should recover panics of the factory function", func(t *testing.T) {
+		for _, workers := range []int{1, 8} {
+			_, err := ss.DecodeSlice[batchUser](newSources(100), func(m map[string]interface{}) ss.TagDecoder {
+				if m["age"] == 42 {
+					panic("fakePanic")
+				}
+				return ss.NewMapTagDecoder("map", m)
+			}, ss.WithWorkers(workers))
+			tt.AssertErrContains(t, err, "error decoding record 42", "panic: fakePanic")
+
+			var panicErr *ss.PanicError
+			tt.AssertTrue(t, errors.As(err, &panicErr), "error %#v should contain a PanicError", err)
+		}
+	})
+
+	t.Run("should raise panics on the caller when the recovery is disabled", func(t *testing.T) {
+		var recovered interface{}
+		func() {
+			defer func() {
+				recovered = recover()
+			}()
+
+			_, _ = ss.DecodeSlice[batchUser](newSources(100), func(m map[string]interface{}) ss.TagDecoder {
+				if m["age"] == 42 || m["age"] == 84 {
+					panic(fmt.Sprint("fakePanic", m["age"]))
+				}
+				return ss.NewMapTagDecoder("map", m)
+			}, ss.WithWorkers(8), ss.DisablePanicRecovery())
+		}()
+		tt.AssertEqual(t, recovered, "fakePanic42")
+	})
+
+	t.Run("should work with more workers than sources", func(t *testing.T) {
+		users, err := ss.DecodeSlice[batchUser](newSources(2), newBatchDecoder, ss.WithWorkers(8))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, users, []batchUser{
+			{Name: "fakeName", Age: 0},
+			{Name: "fakeName", Age: 1},
+		})
+	})
+}
@@ -3,6 +3,10 @@ package structscanner
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/vingarcia/structscanner/internal/types"
 )
 
 // FuncTagDecoder is a simple wrapper for decoders that do not need
@@ -16,10 +20,37 @@ func (e FuncTagDecoder) DecodeField(info Field) (interface{}, error) {
 
 // MapTagDecoder can be used to fill a struct with the values of a map.
 //
-// It works recursively so you can pass nested structs to it.
+// It works recursively so you can pass nested structs to it, the values
+// of nested structs can be either nested maps or flattened keys joined
+// by a separator, e.g. both `{"db": {"host": "x"}}` and `{"db.host": "x"}`
+// fill the Host field of a nested struct with the `map:"db"` tag.
+//
+// Tags can also contain paths for reaching into nested
+// maps, e.g. `map:"server.http.port"`.
+//
+// The separator is "." by default and can be changed with WithSeparator.
+//
+// Nested maps can be of any map type whose keys are strings or
+// basic types convertible to strings, e.g. map[interface{}]interface{}
+// as returned by some YAML parsers or map[string]string.
+//
+// Missing or nil nested maps are treated as not provided, so pointers
+// to nested structs are kept nil, and nested struct values keep the
+// values of their fields while still having their validations checked.
+//
+// It also implements the UnusedKeysReporter interface so the
+// `Strict()` option can report keys that no field asked for.
 type MapTagDecoder struct {
 	tagName   string
 	sourceMap map[string]any
+	keys      *keyTracker
+
+	matching KeyMatching
+	// index maps the normalized keys of the sourceMap to
+	// the original ones when the matching is not exact:
+	index map[string][]string
+
+	separator string
 }
 
 // NewMapTagDecoder returns a new decoder for filling a given struct
@@ -31,25 +62,456 @@ func NewMapTagDecoder(tagName string, sourceMap map[string]interface{}) MapTagDe
 	return MapTagDecoder{
 		tagName:   tagName,
 		sourceMap: sourceMap,
+		keys:      newKeyTracker(),
+		separator: ".",
+	}
+}
+
+// WithSeparator returns a copy of the decoder that uses the given
+// separator for paths and flattened keys, e.g. "__" for keys like
+// "DB__HOST", an empty separator disables both features.
+func (e MapTagDecoder) WithSeparator(separator string) MapTagDecoder {
+	e.separator = separator
+	return e
+}
+
+// KeyMatching is the policy used by the MapTagDecoder
+// for matching the keys of the fields to the keys of the map.
+type KeyMatching int
+
+const (
+	// ExactMatch only matches keys that are equal, it is the default.
+	ExactMatch KeyMatching = iota
+
+	// CaseInsensitiveMatch matches keys ignoring their case,
+	// e.g. "UserName" matches "username".
+	CaseInsensitiveMatch
+
+	// NormalizedMatch matches keys ignoring their case and
+	// any `_` or `-`, e.g. "UserName" matches "user_name".
+	NormalizedMatch
+)
+
+func (m KeyMatching) normalize(key string) string {
+	switch m {
+	case CaseInsensitiveMatch:
+		return strings.ToLower(key)
+	case NormalizedMatch:
+		key = strings.ReplaceAll(key, "_", "")
+		key = strings.ReplaceAll(key, "-", "")
+		return strings.ToLower(key)
+	}
+	return key
+}
+
+// WithKeyMatching returns a copy of the decoder that matches the keys
+// of the fields to the keys of the map using the given policy,
+// this policy also applies to the nested maps.
+//
+// If more than one key of the map matches the key of a field,
+// e.g. "user_name" and "UserName" with NormalizedMatch,
+// DecodeField returns an error since the match is ambiguous.
+func (e MapTagDecoder) WithKeyMatching(matching KeyMatching) MapTagDecoder {
+	e.matching = matching
+	e.index = buildKeyIndex(matching, e.sourceMap)
+	return e
+}
+
+func buildKeyIndex(matching KeyMatching, sourceMap map[string]interface{}) map[string][]string {
+	if matching == ExactMatch {
+		return nil
+	}
+
+	index := make(map[string][]string, len(sourceMap))
+	for key := range sourceMap {
+		normalizedKey := matching.normalize(key)
+		index[normalizedKey] = append(index[normalizedKey], key)
 	}
+	return index
 }
 
 // DecodeField implements the TagDecoder interface
 func (e MapTagDecoder) DecodeField(info Field) (interface{}, error) {
-	key := info.Tags[e.tagName]
-	if info.Kind == reflect.Struct {
-		nestedMap, ok := e.sourceMap[key].(map[string]interface{})
-		if !ok {
+	key := info.Key(e.tagName)
+	if key == "" {
+		return nil, nil
+	}
+
+	isNestedStruct := isStructOrStructPtr(info.Type) && !isSecretType(info.Type) && !info.hasConverter
+	value, err := e.lookup(key, isNestedStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNestedStruct {
+		nestedMap, isMap, err := toStringMap(value)
+		if err != nil {
+			return nil, fmt.Errorf("error reading nested map of field %s: %w", info.Name, err)
+		}
+		if !isMap {
+			// Struct values like time.Time are
+			// not nested structs for the map:
+			if isConvertibleTo(value, info.Type) {
+				return value, nil
+			}
 			return nil, fmt.Errorf(
 				"can't map %T into nested struct %s of type %v",
-				e.sourceMap[key], info.Name, info.Type,
+				value, info.Name, info.Type,
 			)
 		}
+		if nestedMap == nil {
+			if info.Type.Kind() == reflect.Ptr {
+				return nil, nil
+			}
+			nestedMap = map[string]interface{}{}
+		}
 
 		// By returning a decoder you tell the library to run
 		// it recursively on this nestedMap:
-		return NewMapTagDecoder(e.tagName, nestedMap), nil
+		return e.nested(nestedMap), nil
+	}
+
+	isSliceOfStructs := info.Kind == reflect.Slice &&
+		isStructOrStructPtr(info.Type.Elem()) && !isSecretType(info.Type.Elem()) && !info.hasConverter
+	if isSliceOfStructs && value != nil {
+		return e.nestedItems(info, value)
+	}
+
+	return value, nil
+}
+
+// nestedItems returns one decoder for each nested
+// map of a slice, for filling slices of structs.
+func (e MapTagDecoder) nestedItems(info Field, value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || isConvertibleTo(value, info.Type) {
+		return value, nil
+	}
+
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		nestedMap, isMap, err := toStringMap(v.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("error reading nested map of %s[%d]: %w", info.Name, i, err)
+		}
+		if !isMap {
+			return nil, fmt.Errorf(
+				"can't map %T into item %d of %s of type %v",
+				v.Index(i).Interface(), i, info.Name, info.Type,
+			)
+		}
+		if nestedMap == nil {
+			nestedMap = map[string]interface{}{}
+		}
+
+		items[i] = e.nested(nestedMap)
+	}
+
+	return items, nil
+}
+
+func isConvertibleTo(value interface{}, t reflect.Type) bool {
+	valueType := reflect.TypeOf(value)
+	if valueType == nil {
+		return false
+	}
+
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return valueType.ConvertibleTo(t)
+}
+
+// lookup returns the value for the given key, which might be a path
+// into nested maps, or for nested structs, a map with the flattened keys
+// starting with the given key.
+func (e MapTagDecoder) lookup(key string, isNestedStruct bool) (interface{}, error) {
+	value, found, err := e.lookupKey(key)
+	if err != nil || found || e.separator == "" {
+		return value, err
+	}
+
+	// Paths like "server.http.port" are resolved by trying each
+	// separator as the boundary between a key and a nested map,
+	// since the keys themselves might contain the separator:
+	for i := strings.Index(key, e.separator); i != -1; i = nextIndex(key, e.separator, i) {
+		head, rest := key[:i], key[i+len(e.separator):]
+
+		headKey, found, err := e.matchKey(head)
+		if err != nil {
+			return nil, err
+		}
+
+		nestedMap, isMap, err := toStringMap(e.sourceMap[headKey])
+		if !found || !isMap || err != nil || nestedMap == nil {
+			continue
+		}
+
+		// The head is not marked as used, instead the nested decoder
+		// is kept for reporting the keys that no path reached:
+		nested := e.keys.trackNested(headKey, func() UnusedKeysReporter {
+			return e.nested(nestedMap)
+		}).(MapTagDecoder)
+
+		value, err := nested.lookup(rest, isNestedStruct)
+		if err != nil || value != nil {
+			return value, err
+		}
+	}
+
+	if isNestedStruct {
+		nestedMap, err := e.flattenedMap(key)
+		if err != nil || nestedMap != nil {
+			return nestedMap, err
+		}
+	}
+
+	return nil, nil
+}
+
+func nextIndex(s string, substr string, previous int) int {
+	start := previous + len(substr)
+	i := strings.Index(s[start:], substr)
+	if i == -1 {
+		return -1
+	}
+	return start + i
+}
+
+// flattenedMap builds a nested map with the flattened keys that
+// start with the given prefix, e.g. for the prefix "db" the key
+// "db.host" becomes the key "host" of the nested map.
+//
+// It returns nil if there are no keys with the given prefix, and an error
+// if more than one key matches the same nested key, e.g. "DB.host" and
+// "db.host" with CaseInsensitiveMatch.
+func (e MapTagDecoder) flattenedMap(prefix string) (map[string]interface{}, error) {
+	normalizedPrefix := e.matching.normalize(prefix)
+
+	var nestedMap map[string]interface{}
+	sourceKeys := map[string][]string{}
+	for key, value := range e.sourceMap {
+		for i := strings.Index(key, e.separator); i != -1; i = nextIndex(key, e.separator, i) {
+			if e.matching.normalize(key[:i]) != normalizedPrefix {
+				continue
+			}
+
+			if nestedMap == nil {
+				nestedMap = map[string]interface{}{}
+			}
+			nestedKey := key[i+len(e.separator):]
+			nestedMap[nestedKey] = value
+			sourceKeys[nestedKey] = append(sourceKeys[nestedKey], key)
+			e.keys.markUsed(key)
+			break
+		}
 	}
 
-	return e.sourceMap[key], nil
+	for nestedKey, keys := range sourceKeys {
+		if len(keys) > 1 {
+			return nil, newAmbiguousKeyError(prefix+e.separator+nestedKey, keys)
+		}
+	}
+
+	return nestedMap, nil
+}
+
+// lookupKey returns the value of the sourceMap matching the
+// given key according to the KeyMatching of the decoder.
+func (e MapTagDecoder) lookupKey(key string) (value interface{}, found bool, err error) {
+	matchedKey, found, err := e.matchKey(key)
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	e.keys.markUsed(matchedKey)
+	return e.sourceMap[matchedKey], true, nil
+}
+
+// matchKey returns the key of the sourceMap matching the
+// given key according to the KeyMatching of the decoder.
+func (e MapTagDecoder) matchKey(key string) (matchedKey string, found bool, err error) {
+	if e.matching == ExactMatch {
+		_, found := e.sourceMap[key]
+		return key, found, nil
+	}
+
+	matches := e.index[e.matching.normalize(key)]
+	switch len(matches) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return matches[0], true, nil
+	}
+
+	return "", false, newAmbiguousKeyError(key, matches)
+}
+
+func newAmbiguousKeyError(key string, matches []string) error {
+	sortedMatches := append([]string(nil), matches...)
+	sort.Strings(sortedMatches)
+	return fmt.Errorf("ambiguous key %q: it matches all of the keys %q", key, sortedMatches)
+}
+
+// toStringMap converts maps of any type whose keys are strings
+// or basic types into a map[string]interface{}.
+//
+// It returns isMap=false if the value is not a map, and
+// a nil map if the value is nil or a nil map.
+func toStringMap(value interface{}) (_ map[string]interface{}, isMap bool, _ error) {
+	if value == nil {
+		return nil, true, nil
+	}
+
+	if m, ok := value.(map[string]interface{}); ok {
+		return m, true, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Map {
+		return nil, false, nil
+	}
+
+	if v.IsNil() {
+		return nil, true, nil
+	}
+
+	m := make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKeyToString(iter.Key())
+		if err != nil {
+			return nil, true, err
+		}
+		m[key] = iter.Value().Interface()
+	}
+
+	return m, true, nil
+}
+
+func mapKeyToString(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+
+	switch key.Kind() {
+	case reflect.String:
+		return key.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return fmt.Sprint(key.Interface()), nil
+	case reflect.Invalid:
+		return "", fmt.Errorf("unsupported map key: nil")
+	}
+
+	return "", fmt.Errorf("unsupported map key of type %v: %v", key.Type(), key)
+}
+
+// nested returns a decoder for a nested map
+// with the same configuration of this decoder.
+func (e MapTagDecoder) nested(nestedMap map[string]interface{}) MapTagDecoder {
+	return NewMapTagDecoder(e.tagName, nestedMap).
+		WithKeyMatching(e.matching).
+		WithSeparator(e.separator)
+}
+
+// UnusedKeys implements the UnusedKeysReporter interface,
+// the nested maps read through paths report the keys no path
+// reached, e.g. "server.typo" for the path "server.port".
+func (e MapTagDecoder) UnusedKeys() []string {
+	var keys []string
+	for _, key := range e.keys.unusedKeys(e.sourceMap) {
+		nested, found := e.keys.nestedReporter(key)
+		if !found {
+			keys = append(keys, key)
+			continue
+		}
+
+		for _, nestedKey := range nested.UnusedKeys() {
+			keys = append(keys, key+e.separator+nestedKey)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// parseString converts a string read from a text based data source
+// into the type expected by the field, e.g. "42" into 42 for int fields.
+func parseString(info Field, value string) (interface{}, error) {
+	v, err := types.StringToType(info.Type, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Interface(), nil
+}
+
+// StringMapTagDecoder works like the MapTagDecoder but for maps whose
+// values are strings, e.g. values parsed from text files, that need
+// to be parsed into the type of each field, e.g. "42" into 42 for int fields.
+//
+// Nested structs are filled from nested maps of type map[string]interface{}.
+type StringMapTagDecoder struct {
+	tagName   string
+	sourceMap map[string]interface{}
+	keys      *keyTracker
+}
+
+// NewStringMapTagDecoder returns a new decoder for filling a given struct
+// with the values from the sourceMap argument.
+//
+// The values of the sourceMap should either be strings or nested maps
+// of type map[string]interface{} for filling nested structs.
+func NewStringMapTagDecoder(tagName string, sourceMap map[string]interface{}) StringMapTagDecoder {
+	return StringMapTagDecoder{
+		tagName:   tagName,
+		sourceMap: sourceMap,
+		keys:      newKeyTracker(),
+	}
+}
+
+// DecodeField implements the TagDecoder interface
+func (e StringMapTagDecoder) DecodeField(info Field) (interface{}, error) {
+	key := info.Key(e.tagName)
+	value, found := e.sourceMap[key]
+	if !found || key == "" {
+		return nil, nil
+	}
+	e.keys.markUsed(key)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if !isStructOrStructPtr(info.Type) {
+			return nil, fmt.Errorf(
+				"can't map nested map %q into field %s of type %v",
+				key, info.Name, info.Type,
+			)
+		}
+
+		// By returning a decoder you tell the library to run
+		// it recursively on this nestedMap:
+		return NewStringMapTagDecoder(e.tagName, v), nil
+
+	case string:
+		return parseString(info, v)
+	}
+
+	return value, nil
+}
+
+// UnusedKeys implements the UnusedKeysReporter interface
+func (e StringMapTagDecoder) UnusedKeys() []string {
+	return e.keys.unusedKeys(e.sourceMap)
+}
+
+func isStructOrStructPtr(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
 }
@@ -3,6 +3,7 @@ package structscanner_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/vingarcia/structscanner"
 	tt "github.com/vingarcia/structscanner/internal/testtools"
@@ -82,4 +83,331 @@ func TestMapTagDecoder(t *testing.T) {
 
 		tt.AssertErrContains(t, err, "string", "Address", "Street", "City", "Country")
 	})
+
+	t.Run("should match keys using the KeyMatching policy", func(t *testing.T) {
+		type User struct {
+			UserName string `map:"UserName"`
+			Address  struct {
+				ZipCode string `map:"zip_code"`
+			} `map:"address"`
+		}
+
+		tests := []struct {
+			desc             string
+			matching         structscanner.KeyMatching
+			sourceMap        map[string]interface{}
+			expectedUserName string
+			expectedZipCode  string
+
+			// Keys that don't match any field under the chosen policy:
+			expectUnknownKey string
+		}{
+			{
+				desc:     "exact",
+				matching: structscanner.ExactMatch,
+				sourceMap: map[string]interface{}{
+					"UserName": "fakeUserName",
+					"username": "ignoredUserName",
+					"address": map[string]interface{}{
+						"zip_code": "fakeZipCode",
+					},
+				},
+				expectedUserName: "fakeUserName",
+				expectedZipCode:  "fakeZipCode",
+				expectUnknownKey: "username",
+			},
+			{
+				desc:     "case insensitive",
+				matching: structscanner.CaseInsensitiveMatch,
+				sourceMap: map[string]interface{}{
+					"username":  "fakeUserName",
+					"user_name": "ignoredUserName",
+					"Address": map[string]interface{}{
+						"ZIP_CODE": "fakeZipCode",
+					},
+				},
+				expectedUserName: "fakeUserName",
+				expectedZipCode:  "fakeZipCode",
+				expectUnknownKey: "user_name",
+			},
+			{
+				desc:     "normalized",
+				matching: structscanner.NormalizedMatch,
+				sourceMap: map[string]interface{}{
+					"user_name": "fakeUserName",
+					"ADDRESS": map[string]interface{}{
+						"zip-code": "fakeZipCode",
+					},
+				},
+				expectedUserName: "fakeUserName",
+				expectedZipCode:  "fakeZipCode",
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				var user User
+				decoder := structscanner.NewMapTagDecoder("map", test.sourceMap).WithKeyMatching(test.matching)
+				err := structscanner.Decode(&user, decoder, structscanner.Strict())
+				if test.expectUnknownKey != "" {
+					tt.AssertErrContains(t, err, "unknown key", test.expectUnknownKey)
+				} else {
+					tt.AssertNoErr(t, err)
+				}
+				tt.AssertEqual(t, user.UserName, test.expectedUserName)
+				tt.AssertEqual(t, user.Address.ZipCode, test.expectedZipCode)
+			})
+		}
+	})
+
+	t.Run("should report ambiguous keys", func(t *testing.T) {
+		var user struct {
+			UserName string `map:"username"`
+		}
+		decoder := structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"user_name": "fakeUserName1",
+			"UserName":  "fakeUserName2",
+		}).WithKeyMatching(structscanner.NormalizedMatch)
+
+		err := structscanner.Decode(&user, decoder)
+		tt.AssertErrContains(t, err, "UserName", "ambiguous key", "username", `["UserName" "user_name"]`)
+	})
+
+	t.Run("should resolve paths and flattened keys", func(t *testing.T) {
+		var config struct {
+			HTTPPort int    `map:"server.http.port"`
+			Version  string `map:"meta.version"`
+			DB       struct {
+				Host    string `map:"host"`
+				Port    int    `map:"port"`
+				Primary struct {
+					User string `map:"user"`
+				} `map:"primary"`
+			} `map:"db"`
+			Cache struct {
+				TTL int `map:"ttl"`
+			} `map:"cache"`
+		}
+		err := structscanner.Decode(&config, structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"server": map[string]interface{}{
+				"http.port": 8080,
+			},
+			"meta.version":    "fakeVersion",
+			"db.host":         "fakeHost",
+			"db.port":         5432,
+			"db.primary.user": "fakeUser",
+			"cache": map[string]interface{}{
+				"ttl": 60,
+			},
+		}), structscanner.Strict())
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, config.HTTPPort, 8080)
+		tt.AssertEqual(t, config.Version, "fakeVersion")
+		tt.AssertEqual(t, config.DB.Host, "fakeHost")
+		tt.AssertEqual(t, config.DB.Port, 5432)
+		tt.AssertEqual(t, config.DB.Primary.User, "fakeUser")
+		tt.AssertEqual(t, config.Cache.TTL, 60)
+	})
+
+	t.Run("should support custom separators and key matching on flattened keys", func(t *testing.T) {
+		var config struct {
+			DB struct {
+				Host string `map:"host"`
+			} `map:"db"`
+		}
+		decoder := structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"DB__HOST": "fakeHost",
+		}).WithSeparator("__").WithKeyMatching(structscanner.CaseInsensitiveMatch)
+
+		err := structscanner.Decode(&config, decoder)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.DB.Host, "fakeHost")
+	})
+
+	t.Run("should report unknown flattened keys with the path of the nested struct", func(t *testing.T) {
+		var config struct {
+			DB struct {
+				Host string `map:"host"`
+			} `map:"db"`
+		}
+		err := structscanner.Decode(&config, structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"db.host": "fakeHost",
+			"db.hots": "fakeHost",
+		}), structscanner.Strict())
+		tt.AssertErrContains(t, err, "DB", `unknown key "hots", did you mean "host"?`)
+	})
+
+	t.Run("should report unknown keys of nested maps read through paths", func(t *testing.T) {
+		var config struct {
+			Port    int `map:"server.port"`
+			Timeout int `map:"server.http.timeout"`
+		}
+		err := structscanner.Decode(&config, structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"server": map[string]interface{}{
+				"port": 8080,
+				"typo": 42,
+				"http": map[string]interface{}{
+					"timeout": 10,
+					"tiemout": 10,
+				},
+			},
+		}), structscanner.Strict())
+		tt.AssertErrContains(t, err, `unknown key "server.http.tiemout"`, `unknown key "server.typo"`)
+		tt.AssertEqual(t, config.Port, 8080)
+		tt.AssertEqual(t, config.Timeout, 10)
+	})
+
+	t.Run("should report ambiguous flattened keys", func(t *testing.T) {
+		var config struct {
+			DB struct {
+				Host string `map:"host"`
+			} `map:"db"`
+		}
+		decoder := structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"DB.host": "fakeHost1",
+			"db.host": "fakeHost2",
+		}).WithKeyMatching(structscanner.CaseInsensitiveMatch)
+
+		err := structscanner.Decode(&config, decoder)
+		tt.AssertErrContains(t, err, "DB", "ambiguous key", "db.host", `["DB.host" "db.host"]`)
+	})
+
+	t.Run("should not use paths if the separator is disabled", func(t *testing.T) {
+		var config struct {
+			Port int `map:"server.port"`
+		}
+		err := structscanner.Decode(&config, structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"server": map[string]interface{}{
+				"port": 8080,
+			},
+		}).WithSeparator(""))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Port, 0)
+	})
+
+	t.Run("should accept nested maps of any type", func(t *testing.T) {
+		type Address struct {
+			Street string `map:"street"`
+			Number int    `map:"number"`
+		}
+
+		var user struct {
+			Home    Address  `map:"home"`
+			Work    *Address `map:"work"`
+			Billing *Address `map:"billing"`
+			Extra   struct {
+				Tags map[string]string `map:"tags"`
+			} `map:"extra"`
+			Codes struct {
+				One string `map:"1"`
+				Yes string `map:"true"`
+			} `map:"codes"`
+		}
+		err := structscanner.Decode(&user, structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"home": map[interface{}]interface{}{
+				"street": "fakeHomeStreet",
+				"number": 42,
+			},
+			"work": map[string]string{
+				"street": "fakeWorkStreet",
+			},
+			"extra": map[string]interface{}{
+				"tags": map[string]string{"fakeKey": "fakeValue"},
+			},
+			"codes": map[interface{}]interface{}{
+				1:    "fakeOne",
+				true: "fakeYes",
+			},
+		}))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, user.Home, Address{Street: "fakeHomeStreet", Number: 42})
+		tt.AssertEqual(t, user.Work, &Address{Street: "fakeWorkStreet"})
+		tt.AssertEqual(t, user.Billing, (*Address)(nil))
+		tt.AssertEqual(t, user.Extra.Tags, map[string]string{"fakeKey": "fakeValue"})
+		tt.AssertEqual(t, user.Codes.One, "fakeOne")
+		tt.AssertEqual(t, user.Codes.Yes, "fakeYes")
+	})
+
+	t.Run("should treat nil nested maps as not provided", func(t *testing.T) {
+		type Address struct {
+			Street string `map:"street" validate:"nonempty"`
+		}
+
+		user := struct {
+			Work    *Address `map:"work"`
+			Billing *Address `map:"billing"`
+			Home    Address  `map:"home"`
+		}{
+			Home: Address{Street: "fakeDefaultStreet"},
+		}
+		err := structscanner.Decode(&user, structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"work":    nil,
+			"billing": map[string]interface{}(nil),
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, user.Work, (*Address)(nil))
+		tt.AssertEqual(t, user.Billing, (*Address)(nil))
+		tt.AssertEqual(t, user.Home.Street, "fakeDefaultStreet")
+
+		user.Home.Street = ""
+		err = structscanner.Decode(&user, structscanner.NewMapTagDecoder("map", map[string]interface{}{}))
+		tt.AssertErrContains(t, err, "Home.Street", "must not be empty")
+	})
+
+	t.Run("should decode slices of nested maps and struct values", func(t *testing.T) {
+		type Address struct {
+			Street string `map:"street"`
+		}
+
+		var user struct {
+			Addresses []Address   `map:"addresses"`
+			Previous  []*Address  `map:"previous"`
+			CreatedAt time.Time   `map:"created_at"`
+			UpdatedAt *time.Time  `map:"updated_at"`
+			Copies    []Address   `map:"copies"`
+			Nothing   []*Address  `map:"nothing"`
+			Items     []time.Time `map:"items"`
+		}
+		err := structscanner.Decode(&user, structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"addresses": []interface{}{
+				map[string]interface{}{"street": "fakeStreet1"},
+				map[string]string{"street": "fakeStreet2"},
+			},
+			"previous": []map[string]interface{}{
+				{"street": "fakeStreet3"},
+			},
+			"created_at": tt.ParseTime(t, "2023-01-02T03:04:05Z"),
+			"updated_at": tt.ParseTime(t, "2023-01-02T03:04:05Z"),
+			"copies":     []Address{{Street: "fakeStreet4"}},
+			"items":      []time.Time{tt.ParseTime(t, "2023-01-02T03:04:05Z")},
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, user.Addresses, []Address{{Street: "fakeStreet1"}, {Street: "fakeStreet2"}})
+		tt.AssertEqual(t, user.Previous, []*Address{{Street: "fakeStreet3"}})
+		tt.AssertEqual(t, user.CreatedAt, tt.ParseTime(t, "2023-01-02T03:04:05Z"))
+		tt.AssertEqual(t, *user.UpdatedAt, tt.ParseTime(t, "2023-01-02T03:04:05Z"))
+		tt.AssertEqual(t, user.Copies, []Address{{Street: "fakeStreet4"}})
+		tt.AssertEqual(t, user.Nothing, []*Address(nil))
+		tt.AssertEqual(t, len(user.Items), 1)
+
+		err = structscanner.Decode(&user, structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"addresses": []interface{}{"notAMap"},
+		}))
+		tt.AssertErrContains(t, err, "Addresses", "item 0", "string")
+	})
+
+	t.Run("should report nested maps with unsupported keys", func(t *testing.T) {
+		var user struct {
+			Address struct {
+				Street string `map:"street"`
+			} `map:"address"`
+		}
+		err := structscanner.Decode(&user, structscanner.NewMapTagDecoder("map", map[string]interface{}{
+			"address": map[interface{}]interface{}{
+				[2]int{1, 2}: "fakeValue",
+			},
+		}))
+		tt.AssertErrContains(t, err, "Address", "unsupported map key", "[2]int")
+	})
 }
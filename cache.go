@@ -0,0 +1,187 @@
+package structscanner
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Cache stores the information about the fields of each struct type,
+// which is computed with reflection and parsed from the struct tags
+// only once per type, so that decoding can be done efficiently.
+//
+// By default all the functions of this package share the cache returned
+// by DefaultCache(), which is unbounded since most programs only decode
+// a few struct types, and each Scanner created with New has a private
+// one. Programs that create types dynamically, e.g. with reflect.StructOf,
+// can use a bounded cache instead:
+//
+//	cache := structscanner.NewCache(1000)
+//	err := structscanner.Decode(&target, decoder, structscanner.WithCache(cache))
+//
+// The zero value is an empty unbounded cache ready to use,
+// and it is safe for concurrent use.
+type Cache struct {
+	// The fields used with sync/atomic come first
+	// so they are aligned on 32-bit platforms:
+	hits       uint64
+	misses     uint64
+	numEntries int64
+
+	maxEntries int
+
+	// Unbounded caches only use the sync.Map, so
+	// reading from them doesn't require any locks:
+	entries sync.Map
+
+	// Bounded caches also keep the cacheEntries ordered from
+	// the most recently used to the least recently used:
+	mutex     sync.Mutex
+	lru       list.List
+	lruByType map[reflect.Type]*list.Element
+}
+
+type cacheEntry struct {
+	ptrType reflect.Type
+	fields  []Field
+}
+
+// CacheStats describes the usage of a Cache since it was created or reset.
+type CacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+var defaultCache = NewCache(0)
+
+// DefaultCache returns the cache of the default Scanner, used by the
+// package-level functions when no other cache is passed with the
+// `WithCache()` option, e.g. for resetting it between tests that
+// need a cold cache.
+func DefaultCache() *Cache {
+	return defaultCache
+}
+
+// NewCache returns a new empty cache that holds at most maxEntries
+// struct types, once it is full the least recently used type is
+// evicted for storing a new one.
+//
+// If maxEntries is zero or negative the cache has no size limit.
+func NewCache(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+	}
+}
+
+// WithCache makes Decode store the struct information on the given
+// cache instead of the cache of the Scanner, which for the package-level
+// functions is the one returned by DefaultCache().
+//
+// A nil cache is ignored.
+func WithCache(cache *Cache) Option {
+	return func(o *options) {
+		if cache != nil {
+			o.cache = cache
+		}
+	}
+}
+
+// Stats returns the number of hits, misses and entries of the cache.
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:    atomic.LoadUint64(&c.hits),
+		Misses:  atomic.LoadUint64(&c.misses),
+		Entries: int(atomic.LoadInt64(&c.numEntries)),
+	}
+}
+
+// Reset removes all the entries of the cache and zeroes its stats.
+func (c *Cache) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries.Range(func(key, _ interface{}) bool {
+		if _, loaded := c.entries.LoadAndDelete(key); loaded {
+			atomic.AddInt64(&c.numEntries, -1)
+		}
+		return true
+	})
+	atomic.AddInt64(&c.numEntries, -int64(c.lru.Len()))
+	c.lru.Init()
+	c.lruByType = nil
+
+	atomic.StoreUint64(&c.hits, 0)
+	atomic.StoreUint64(&c.misses, 0)
+}
+
+func (c *Cache) load(ptrType reflect.Type) ([]Field, bool) {
+	if c.maxEntries > 0 {
+		return c.loadLRU(ptrType)
+	}
+
+	fields, found := c.entries.Load(ptrType)
+	if !found {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+
+	atomic.AddUint64(&c.hits, 1)
+	return fields.([]Field), true
+}
+
+func (c *Cache) store(ptrType reflect.Type, fields []Field) {
+	if c.maxEntries > 0 {
+		c.storeLRU(ptrType, fields)
+		return
+	}
+
+	// The same type might be stored twice if it
+	// was loaded concurrently before being cached:
+	_, loaded := c.entries.LoadOrStore(ptrType, fields)
+	if !loaded {
+		atomic.AddInt64(&c.numEntries, 1)
+	}
+}
+
+func (c *Cache) loadLRU(ptrType reflect.Type) ([]Field, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, found := c.lruByType[ptrType]
+	if !found {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+
+	atomic.AddUint64(&c.hits, 1)
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).fields, true
+}
+
+func (c *Cache) storeLRU(ptrType reflect.Type, fields []Field) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, found := c.lruByType[ptrType]; found {
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	if c.lruByType == nil {
+		c.lruByType = map[reflect.Type]*list.Element{}
+	}
+	c.lruByType[ptrType] = c.lru.PushFront(&cacheEntry{
+		ptrType: ptrType,
+		fields:  fields,
+	})
+	atomic.AddInt64(&c.numEntries, 1)
+
+	if c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.lruByType, oldest.Value.(*cacheEntry).ptrType)
+		atomic.AddInt64(&c.numEntries, -1)
+	}
+}
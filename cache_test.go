@@ -0,0 +1,166 @@
+package structscanner_test
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestCache(t *testing.T) {
+	type DB struct {
+		User string `map:"user"`
+	}
+	type Config struct {
+		Host string `map:"host"`
+		DB   DB     `map:"db"`
+	}
+
+	decoder := ss.NewMapTagDecoder("map", map[string]interface{}{
+		"host": "fakeHost",
+		"db": map[string]interface{}{
+			"user": "fakeUser",
+		},
+	})
+
+	t.Run("should count the hits and misses of a private cache", func(t *testing.T) {
+		cache := ss.NewCache(0)
+		tt.AssertEqual(t, cache.Stats(), ss.CacheStats{})
+
+		var config Config
+		err := ss.Decode(&config, decoder, ss.WithCache(cache))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.DB.User, "fakeUser")
+		tt.AssertEqual(t, cache.Stats(), ss.CacheStats{
+			Hits:    0,
+			Misses:  2,
+			Entries: 2,
+		})
+
+		err = ss.Decode(&config, decoder, ss.WithCache(cache))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, cache.Stats(), ss.CacheStats{
+			Hits:    2,
+			Misses:  2,
+			Entries: 2,
+		})
+	})
+
+	t.Run("should be safe for concurrent use", func(t *testing.T) {
+		for _, cache := range []*ss.Cache{ss.NewCache(0), ss.NewCache(1)} {
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						var config Config
+						err := ss.Decode(&config, decoder, ss.WithCache(cache))
+						tt.AssertNoErr(t, err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			stats := cache.Stats()
+			tt.AssertEqual(t, stats.Hits+stats.Misses, uint64(8*100*2))
+		}
+	})
+
+	t.Run("should work with the zero value", func(t *testing.T) {
+		var cache ss.Cache
+
+		var config Config
+		err := ss.Decode(&config, decoder, ss.WithCache(&cache))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, cache.Stats().Entries, 2)
+	})
+
+	t.Run("should reset the entries and stats", func(t *testing.T) {
+		for _, maxEntries := range []int{0, 2} {
+			t.Run(fmt.Sprintf("maxEntries=%d", maxEntries), func(t *testing.T) {
+				cache := ss.NewCache(maxEntries)
+
+				var config Config
+				err := ss.Decode(&config, decoder, ss.WithCache(cache))
+				tt.AssertNoErr(t, err)
+
+				cache.Reset()
+				tt.AssertEqual(t, cache.Stats(), ss.CacheStats{})
+
+				err = ss.Decode(&config, decoder, ss.WithCache(cache))
+				tt.AssertNoErr(t, err)
+				tt.AssertEqual(t, cache.Stats(), ss.CacheStats{
+					Hits:    0,
+					Misses:  2,
+					Entries: 2,
+				})
+			})
+		}
+	})
+
+	t.Run("should ignore a nil cache", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, decoder, ss.WithCache(nil))
+		tt.AssertNoErr(t, err)
+
+		scanner := ss.New(ss.WithCache(nil))
+		_, err = scanner.GetStructInfo(&config)
+		tt.AssertNoErr(t, err)
+		tt.AssertTrue(t, scanner.Cache() != nil, "the scanner should keep its own cache")
+	})
+
+	t.Run("should evict the least recently used types when bounded", func(t *testing.T) {
+		cache := ss.NewCache(2)
+
+		newType := func(i int) reflect.Type {
+			return reflect.StructOf([]reflect.StructField{{
+				Name: "Value",
+				Type: reflect.TypeOf(""),
+				Tag:  reflect.StructTag(fmt.Sprintf(`map:"value%d"`, i)),
+			}})
+		}
+		decodeType := func(typ reflect.Type) {
+			target := reflect.New(typ).Interface()
+			err := ss.Decode(target, ss.NewMapTagDecoder("map", map[string]interface{}{}), ss.WithCache(cache))
+			tt.AssertNoErr(t, err)
+		}
+
+		type0, type1, type2 := newType(0), newType(1), newType(2)
+		decodeType(type0)
+		decodeType(type1)
+		decodeType(type0)
+		decodeType(type2)
+		tt.AssertEqual(t, cache.Stats(), ss.CacheStats{
+			Hits:    1,
+			Misses:  3,
+			Entries: 2,
+		})
+
+		// type1 was the least recently used so it was evicted:
+		decodeType(type0)
+		decodeType(type2)
+		decodeType(type1)
+		tt.AssertEqual(t, cache.Stats(), ss.CacheStats{
+			Hits:    3,
+			Misses:  4,
+			Entries: 2,
+		})
+	})
+
+	t.Run("should use the default cache when no cache is passed", func(t *testing.T) {
+		cache := ss.DefaultCache()
+		cache.Reset()
+
+		_, err := ss.GetStructInfo(&Config{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, cache.Stats(), ss.CacheStats{
+			Hits:    0,
+			Misses:  1,
+			Entries: 1,
+		})
+	})
+}
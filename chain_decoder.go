@@ -0,0 +1,122 @@
+package structscanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ChainTagDecoder returns a decoder that combines several data sources
+// in order of priority, e.g.:
+//
+//	decoder := structscanner.ChainTagDecoder(flagsDecoder, envDecoder, fileDecoder, defaultsDecoder)
+//
+// For each field each decoder is asked for a value in the order they were
+// passed and the first non-nil value is used, so a source only overrides
+// the fields it actually provides.
+//
+// When the value returned for a nested struct is a TagDecoder the nested
+// decoders of all the sources are combined into a new chain, so the fields
+// of nested structs are also resolved one by one.
+//
+// With the `Strict()` option the chain reports the unused keys of all
+// its sources, and since a key is only unused if no field asked for it,
+// the key of each field is also marked as used on the lower priority
+// sources that track their unused keys, without reading their values,
+// e.g. the FileTagDecoder doesn't read the files of these keys.
+func ChainTagDecoder(decoders ...TagDecoder) TagDecoder {
+	return chainTagDecoder{
+		decoders: decoders,
+	}
+}
+
+type chainTagDecoder struct {
+	decoders []TagDecoder
+}
+
+// DecodeField implements the TagDecoder interface
+func (c chainTagDecoder) DecodeField(info Field) (interface{}, error) {
+	return c.DecodeFieldContext(context.Background(), info)
+}
+
+// DecodeFieldContext implements the ContextTagDecoder interface
+func (c chainTagDecoder) DecodeFieldContext(ctx context.Context, info Field) (interface{}, error) {
+	value, _, err := c.decodeFieldWithSource(ctx, info)
+	return value, err
+}
+
+// decodeFieldWithSource also returns the decoder that provided
+// the value, so Decode can report the source of each field.
+func (c chainTagDecoder) decodeFieldWithSource(ctx context.Context, info Field) (interface{}, TagDecoder, error) {
+	var nestedDecoders []TagDecoder
+	for i, decoder := range c.decoders {
+		value, source, err := decodeFieldWithSource(ctx, decoder, info)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error on decoder %d of the chain: %w", i, err)
+		}
+
+		if value == nil {
+			continue
+		}
+
+		nestedDecoder, isDecoder := value.(TagDecoder)
+		if !isDecoder {
+			if nestedDecoders != nil {
+				// A higher priority source already provided this
+				// nested struct, so values that are not decoders
+				// can't be merged with it and are ignored:
+				continue
+			}
+			if isStrict(ctx) {
+				c.markUsed(ctx, i+1, info)
+			}
+			return value, source, nil
+		}
+
+		nestedDecoders = append(nestedDecoders, nestedDecoder)
+	}
+
+	switch len(nestedDecoders) {
+	case 0:
+		return nil, c, nil
+	case 1:
+		return nestedDecoders[0], nestedDecoders[0], nil
+	}
+
+	nestedChain := chainTagDecoder{
+		decoders: nestedDecoders,
+	}
+	return nestedChain, nestedChain, nil
+}
+
+// markUsed marks the key of the field as used on the
+// decoders starting at index start, see markKeyUsed.
+func (c chainTagDecoder) markUsed(ctx context.Context, start int, info Field) {
+	for _, decoder := range c.decoders[start:] {
+		markKeyUsed(ctx, decoder, info)
+	}
+}
+
+// markKeyUsed implements the keyMarker interface
+func (c chainTagDecoder) markKeyUsed(ctx context.Context, info Field) {
+	c.markUsed(ctx, 0, info)
+}
+
+// UnusedKeys implements the UnusedKeysReporter interface
+// by returning the unused keys of all the sources.
+func (c chainTagDecoder) UnusedKeys() []string {
+	seen := map[string]bool{}
+	var unused []string
+	for _, decoder := range c.decoders {
+		keys, _ := unusedKeys(decoder)
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				unused = append(unused, key)
+			}
+		}
+	}
+
+	sort.Strings(unused)
+	return unused
+}
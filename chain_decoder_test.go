@@ -0,0 +1,121 @@
+package structscanner_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestChainTagDecoder(t *testing.T) {
+	type Config struct {
+		Host    string `map:"host" env:"HOST"`
+		Port    int    `map:"port" env:"PORT"`
+		Debug   bool   `map:"debug" env:"DEBUG"`
+		Retries int    `map:"retries" env:"RETRIES"`
+		DB      struct {
+			User     string `map:"user" env:"DB_USER"`
+			Password string `map:"password" env:"DB_PASSWORD"`
+			Name     string `map:"name" env:"DB_NAME"`
+		} `map:"db"`
+	}
+
+	// envDecoder simulates a source that only has values for some of the fields:
+	newEnvDecoder := func(env map[string]interface{}) ss.TagDecoder {
+		var decoder ss.FuncTagDecoder
+		decoder = func(field ss.Field) (interface{}, error) {
+			if field.Kind == reflect.Struct {
+				return decoder, nil
+			}
+			return env[field.Tags["env"]], nil
+		}
+		return decoder
+	}
+
+	t.Run("should use the first non-nil value for each field", func(t *testing.T) {
+		defaults := ss.NewMapTagDecoder("map", map[string]interface{}{
+			"host":    "localhost",
+			"port":    8080,
+			"debug":   true,
+			"retries": 3,
+			"db": map[string]interface{}{
+				"user": "defaultUser",
+				"name": "defaultName",
+			},
+		})
+		file := ss.NewMapTagDecoder("map", map[string]interface{}{
+			"port": 9090,
+			"db": map[string]interface{}{
+				"user":     "fileUser",
+				"password": "filePassword",
+			},
+		})
+		env := newEnvDecoder(map[string]interface{}{
+			"HOST":        "envHost",
+			"DEBUG":       false,
+			"DB_PASSWORD": "envPassword",
+		})
+
+		var config Config
+		err := ss.Decode(&config, ss.ChainTagDecoder(env, file, defaults))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, config.Host, "envHost")
+		tt.AssertEqual(t, config.Port, 9090)
+		// Zero values are still values, so they override lower priority sources:
+		tt.AssertEqual(t, config.Debug, false)
+		tt.AssertEqual(t, config.Retries, 3)
+		tt.AssertEqual(t, config.DB.User, "fileUser")
+		tt.AssertEqual(t, config.DB.Password, "envPassword")
+		tt.AssertEqual(t, config.DB.Name, "defaultName")
+	})
+
+	t.Run("should keep the current value of fields no source provides", func(t *testing.T) {
+		config := Config{Host: "placeholder"}
+		err := ss.Decode(&config, ss.ChainTagDecoder(
+			newEnvDecoder(map[string]interface{}{}),
+			ss.NewMapTagDecoder("map", map[string]interface{}{
+				"port": 42,
+				"db":   map[string]interface{}{},
+			}),
+		))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Host, "placeholder")
+		tt.AssertEqual(t, config.Port, 42)
+	})
+
+	t.Run("should not merge nested decoders with values of lower priority", func(t *testing.T) {
+		type Nested struct {
+			Name string `map:"name"`
+		}
+		var output struct {
+			Nested Nested `map:"nested"`
+		}
+		err := ss.Decode(&output, ss.ChainTagDecoder(
+			ss.NewMapTagDecoder("map", map[string]interface{}{
+				"nested": map[string]interface{}{},
+			}),
+			ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+				return Nested{Name: "ignored"}, nil
+			}),
+		))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, output.Nested.Name, "")
+	})
+
+	t.Run("should report errors from any of the decoders", func(t *testing.T) {
+		fakeErr := errors.New("fakeErrMsg")
+
+		var config Config
+		err := ss.Decode(&config, ss.ChainTagDecoder(
+			newEnvDecoder(map[string]interface{}{}),
+			ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+				return nil, fakeErr
+			}),
+		))
+		tt.AssertErrContains(t, err, "decoder 1", "fakeErrMsg")
+		tt.AssertTrue(t, errors.Is(err, fakeErr), "error %#v should wrap %#v", err, fakeErr)
+	})
+}
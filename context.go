@@ -0,0 +1,71 @@
+package structscanner
+
+import "context"
+
+// ContextTagDecoder is an optional interface for decoders that read
+// from slow data sources, e.g. files on network mounts, and need
+// to be cancelled or to respect deadlines.
+//
+// When decoding with DecodeContext the DecodeFieldContext method is
+// called instead of DecodeField on the decoders that implement it,
+// including the nested decoders they return.
+type ContextTagDecoder interface {
+	DecodeFieldContext(ctx context.Context, field Field) (interface{}, error)
+}
+
+// ContextFuncTagDecoder works like the FuncTagDecoder
+// but for functions that receive a context.
+type ContextFuncTagDecoder func(ctx context.Context, info Field) (interface{}, error)
+
+// DecodeField implements the TagDecoder interface
+// by calling the function with context.Background().
+func (e ContextFuncTagDecoder) DecodeField(info Field) (interface{}, error) {
+	return e(context.Background(), info)
+}
+
+// DecodeFieldContext implements the ContextTagDecoder interface
+func (e ContextFuncTagDecoder) DecodeFieldContext(ctx context.Context, info Field) (interface{}, error) {
+	return e(ctx, info)
+}
+
+// DecodeContext works like Decode but it stops with the error of the
+// ctx as soon as it is cancelled, it is checked before each field,
+// and it passes the ctx to the decoders that implement the
+// ContextTagDecoder interface, plain TagDecoders are also accepted.
+func DecodeContext(ctx context.Context, targetStruct interface{}, decoder TagDecoder, opts ...Option) error {
+	return defaultScanner.DecodeContext(ctx, targetStruct, decoder, opts...)
+}
+
+// DecodeContext works like the DecodeContext function
+// but using the configuration of this Scanner.
+func (s *Scanner) DecodeContext(ctx context.Context, targetStruct interface{}, decoder TagDecoder, opts ...Option) error {
+	return newDecodeState(ctx, s.parseOptions(opts)).decode(targetStruct, decoder)
+}
+
+// optionsContextKey stores the options of the current decoding
+// on the context passed to the decoders, so decoders like the
+// ChainTagDecoder and the StructTagDecoder can use them.
+type optionsContextKey struct{}
+
+// isStrict returns true if the `Strict()` option is set
+// so the decoders can track their unused keys.
+func isStrict(ctx context.Context) bool {
+	o, _ := ctx.Value(optionsContextKey{}).(options)
+	return o.strict
+}
+
+// activeCache returns the cache of the current decoding.
+func activeCache(ctx context.Context) *Cache {
+	o, _ := ctx.Value(optionsContextKey{}).(options)
+	if o.cache == nil {
+		return defaultCache
+	}
+	return o.cache
+}
+
+func decodeFieldContext(ctx context.Context, decoder TagDecoder, info Field) (interface{}, error) {
+	if d, ok := decoder.(ContextTagDecoder); ok {
+		return d.DecodeFieldContext(ctx, info)
+	}
+	return decoder.DecodeField(info)
+}
@@ -0,0 +1,104 @@
+package structscanner_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+type contextTestKey struct{}
+
+func TestDecodeContext(t *testing.T) {
+	type Config struct {
+		Attr1  string `map:"attr1"`
+		Attr2  string `map:"attr2"`
+		Nested struct {
+			Attr3 string `map:"attr3"`
+		} `map:"nested"`
+	}
+
+	// newContextDecoder returns a decoder that reads the values from the
+	// context, so we can check the ctx is passed to nested decoders:
+	var newContextDecoder func(fields map[string]interface{}) ss.TagDecoder
+	newContextDecoder = func(fields map[string]interface{}) ss.TagDecoder {
+		return ss.ContextFuncTagDecoder(func(ctx context.Context, field ss.Field) (interface{}, error) {
+			value := fields[field.Tags["map"]]
+			if nested, ok := value.(map[string]interface{}); ok {
+				return newContextDecoder(nested), nil
+			}
+
+			if value == "fromContext" {
+				return ctx.Value(contextTestKey{}), nil
+			}
+			return value, nil
+		})
+	}
+
+	t.Run("should pass the context to the decoders", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), contextTestKey{}, "fakeContextValue")
+
+		var config Config
+		err := ss.DecodeContext(ctx, &config, ss.ChainTagDecoder(
+			ss.NamedTagDecoder("fakeName", newContextDecoder(map[string]interface{}{
+				"attr1": "fromContext",
+				"nested": map[string]interface{}{
+					"attr3": "fromContext",
+				},
+			})),
+			ss.NewMapTagDecoder("map", map[string]interface{}{
+				"attr2": "fakeAttr2",
+			}),
+		))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Attr1, "fakeContextValue")
+		tt.AssertEqual(t, config.Attr2, "fakeAttr2")
+		tt.AssertEqual(t, config.Nested.Attr3, "fakeContextValue")
+	})
+
+	t.Run("should use context.Background() when called with Decode", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, newContextDecoder(map[string]interface{}{
+			"attr1":  "fakeAttr1",
+			"nested": map[string]interface{}{},
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Attr1, "fakeAttr1")
+	})
+
+	t.Run("should stop decoding when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var calls []string
+		config := Config{Attr2: "fakeDefault"}
+		err := ss.DecodeContext(ctx, &config, ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+			calls = append(calls, field.Name)
+			cancel()
+			return "fakeValue", nil
+		}))
+		tt.AssertErrContains(t, err, "Attr2", "context canceled")
+		tt.AssertTrue(t, errors.Is(err, context.Canceled), "error %#v should wrap context.Canceled", err)
+
+		var fieldErr *ss.FieldError
+		tt.AssertTrue(t, errors.As(err, &fieldErr), "error %#v should be a FieldError", err)
+		tt.AssertEqual(t, fieldErr.Path, "Attr2")
+
+		tt.AssertEqual(t, calls, []string{"Attr1"})
+		tt.AssertEqual(t, config.Attr1, "fakeValue")
+		tt.AssertEqual(t, config.Attr2, "fakeDefault")
+	})
+
+	t.Run("should stop decoding when the deadline is exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+
+		var config Config
+		err := ss.DecodeContext(ctx, &config, ss.NewMapTagDecoder("map", map[string]interface{}{}))
+		tt.AssertTrue(t, errors.Is(err, context.DeadlineExceeded), "error %#v should wrap context.DeadlineExceeded", err)
+	})
+}
@@ -0,0 +1,77 @@
+package structscanner
+
+import (
+	"reflect"
+
+	"github.com/vingarcia/structscanner/internal/types"
+)
+
+// ConverterFunc converts a raw value returned by a decoder
+// into a value of the type the converter was registered for.
+type ConverterFunc func(value interface{}) (interface{}, error)
+
+// RegisterConverter registers a function for converting the raw
+// values of the fields of type targetType, replacing any converter
+// registered for the same type, e.g.:
+//
+//	structscanner.RegisterConverter(reflect.TypeOf(net.IP{}), func(value interface{}) (interface{}, error) {
+//		ip := net.ParseIP(fmt.Sprint(value))
+//		if ip == nil {
+//			return nil, fmt.Errorf("invalid IP address: %v", value)
+//		}
+//		return ip, nil
+//	})
+//
+// The converter is also used for fields of type *T and for the items of
+// slices of T, and the built-in conversions are applied to its output.
+//
+// The converter is registered on the default Scanner, use
+// `Scanner.RegisterConverter()` for registering it on another instance.
+func RegisterConverter(targetType reflect.Type, fn ConverterFunc) {
+	defaultScanner.RegisterConverter(targetType, fn)
+}
+
+// RegisterConverter works like the RegisterConverter
+// function but only for this Scanner instance.
+func (s *Scanner) RegisterConverter(targetType reflect.Type, fn ConverterFunc) {
+	s.registryMutex.Lock()
+	defer s.registryMutex.Unlock()
+	s.converterFuncs[targetType] = fn
+}
+
+func (s *Scanner) getConverterFunc(targetType reflect.Type) (ConverterFunc, bool) {
+	s.registryMutex.RLock()
+	defer s.registryMutex.RUnlock()
+	fn, found := s.converterFuncs[targetType]
+	if !found && targetType.Kind() == reflect.Ptr {
+		fn, found = s.converterFuncs[targetType.Elem()]
+	}
+	return fn, found
+}
+
+// hasConverter returns true if there is a converter for the type t
+// or for the items of t, so the decoders can return the raw values
+// of these fields instead of decoding them as nested structs.
+func (s *Scanner) hasConverter(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		if _, found := s.getConverterFunc(t.Elem()); found {
+			return true
+		}
+	}
+	_, found := s.getConverterFunc(t)
+	return found
+}
+
+// convert converts rawValue into targetType using the converters
+// registered on the Scanner before the built-in conversions.
+func (d *decodeState) convert(rawValue interface{}, targetType reflect.Type) (reflect.Value, error) {
+	if fn, found := d.scanner.getConverterFunc(targetType); found && rawValue != nil {
+		var err error
+		rawValue, err = fn(rawValue)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return types.NewConverter(rawValue).Convert(targetType)
+}
@@ -0,0 +1,169 @@
+package structscanner
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CSVDecoder can be used to fill structs with the records of a CSV file.
+//
+// The first record is read as the header and each column is mapped
+// to the field with the same name on the `csv` tag, columns can also be
+// referenced by their zero based index with a `#` prefix, e.g. `csv:"#3"`.
+//
+// Records are read one at a time, so big files can be decoded
+// without loading them into memory:
+//
+//	decoder, err := structscanner.NewCSVDecoder(csv.NewReader(file))
+//	...
+//	for decoder.Next() {
+//		var user User
+//		err := decoder.Decode(&user)
+//		...
+//	}
+//	if decoder.Err() != nil {
+//		...
+//	}
+type CSVDecoder struct {
+	tagName string
+	reader  *csv.Reader
+	header  []string
+	columns map[string]int
+
+	record []string
+	err    error
+}
+
+// CSVError describes an error that occurred while
+// decoding a specific cell of a CSV file.
+type CSVError struct {
+	Line   int
+	Column int
+	Header string
+	Err    error
+}
+
+func (e CSVError) Error() string {
+	return fmt.Sprintf("csv error on line %d, column %d (%s): %s", e.Line, e.Column, e.Header, e.Err)
+}
+
+func (e CSVError) Unwrap() error {
+	return e.Err
+}
+
+// NewCSVDecoder reads the header from the input reader and
+// returns a decoder for the remaining records.
+func NewCSVDecoder(reader *csv.Reader) (*CSVDecoder, error) {
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	return &CSVDecoder{
+		tagName: "csv",
+		reader:  reader,
+		header:  header,
+		columns: columns,
+	}, nil
+}
+
+// Next reads the next record from the CSV file, it returns false
+// when there are no more records or if an error occurs, in which
+// case the error is made available through the Err() method.
+func (c *CSVDecoder) Next() bool {
+	if c.err != nil {
+		return false
+	}
+
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		c.record = nil
+		return false
+	}
+	if err != nil {
+		c.record = nil
+		c.err = err
+		return false
+	}
+
+	c.record = record
+	return true
+}
+
+// Err returns the error that caused Next() to stop, if any.
+func (c *CSVDecoder) Err() error {
+	return c.err
+}
+
+// Decode fills the targetStruct with the values of the current record,
+// the opts are passed to the Decode function.
+func (c *CSVDecoder) Decode(targetStruct interface{}, opts ...Option) error {
+	if c.record == nil {
+		return fmt.Errorf("no csv record available for decoding, did you call Next()?")
+	}
+
+	return parseOptions(opts).scanner.Decode(targetStruct, c, opts...)
+}
+
+// DecodeField implements the TagDecoder interface
+func (c *CSVDecoder) DecodeField(info Field) (interface{}, error) {
+	idx, found, err := c.columnIndex(info)
+	if err != nil {
+		return nil, err
+	}
+	if !found || idx >= len(c.record) {
+		return nil, nil
+	}
+
+	cell := c.record[idx]
+	if cell == "" && info.Kind != reflect.String {
+		return nil, nil
+	}
+
+	value, err := parseString(info, cell)
+	if err != nil {
+		line, column := c.reader.FieldPos(idx)
+		return nil, CSVError{
+			Line:   line,
+			Column: column,
+			Header: c.headerName(idx),
+			Err:    err,
+		}
+	}
+
+	return value, nil
+}
+
+func (c *CSVDecoder) columnIndex(info Field) (int, bool, error) {
+	key := info.Key(c.tagName)
+	if key == "" {
+		return 0, false, nil
+	}
+
+	if strings.HasPrefix(key, "#") {
+		idx, err := strconv.Atoi(key[1:])
+		if err != nil || idx < 0 {
+			return 0, false, fmt.Errorf("invalid csv column index on tag: %q", key)
+		}
+		return idx, true, nil
+	}
+
+	idx, found := c.columns[key]
+	return idx, found, nil
+}
+
+func (c *CSVDecoder) headerName(idx int) string {
+	if idx < len(c.header) {
+		return c.header[idx]
+	}
+	return "#" + strconv.Itoa(idx)
+}
@@ -0,0 +1,147 @@
+package structscanner_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestCSVDecoder(t *testing.T) {
+	type User struct {
+		ID     int      `csv:"id"`
+		Name   string   `csv:"name"`
+		Score  *float64 `csv:"score"`
+		Active bool     `csv:"#3"`
+	}
+
+	t.Run("should decode records one by one", func(t *testing.T) {
+		decoder, err := ss.NewCSVDecoder(csv.NewReader(strings.NewReader(
+			"id,name,score,active\n" +
+				"1,fakeName1,4.5,true\n" +
+				"2,fakeName2,,false\n",
+		)))
+		tt.AssertNoErr(t, err)
+
+		var users []User
+		for decoder.Next() {
+			var user User
+			err := decoder.Decode(&user)
+			tt.AssertNoErr(t, err)
+			users = append(users, user)
+		}
+		tt.AssertNoErr(t, decoder.Err())
+
+		score := 4.5
+		tt.AssertEqual(t, users, []User{
+			{ID: 1, Name: "fakeName1", Score: &score, Active: true},
+			{ID: 2, Name: "fakeName2", Score: nil, Active: false},
+		})
+	})
+
+	t.Run("should use the configuration of the WithScanner option", func(t *testing.T) {
+		decoder, err := ss.NewCSVDecoder(csv.NewReader(strings.NewReader(
+			"user_id,full_name\n" +
+				"42,fakeName\n",
+		)))
+		tt.AssertNoErr(t, err)
+
+		var user struct {
+			UserID   int
+			FullName string `transform:"shout"`
+		}
+
+		scanner := ss.New(ss.WithNaming(ss.SnakeCase))
+		scanner.RegisterTransform("shout", func(value string) (string, error) {
+			return strings.ToUpper(value) + "!", nil
+		})
+
+		tt.AssertTrue(t, decoder.Next())
+		err = decoder.Decode(&user, ss.WithScanner(scanner))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, user.UserID, 42)
+		tt.AssertEqual(t, user.FullName, "FAKENAME!")
+	})
+
+	t.Run("should ignore fields whose column is missing", func(t *testing.T) {
+		decoder, err := ss.NewCSVDecoder(csv.NewReader(strings.NewReader(
+			"name,id\n" +
+				"fakeName,42\n",
+		)))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertTrue(t, decoder.Next())
+		user := User{Active: true}
+		err = decoder.Decode(&user)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, user, User{ID: 42, Name: "fakeName", Active: true})
+	})
+
+	t.Run("should report the line and column of conversion errors", func(t *testing.T) {
+		decoder, err := ss.NewCSVDecoder(csv.NewReader(strings.NewReader(
+			"id,name,score,active\n" +
+				"1,fakeName1,4.5,true\n" +
+				"2,fakeName2,not-a-float,true\n",
+		)))
+		tt.AssertNoErr(t, err)
+
+		var user User
+		tt.AssertTrue(t, decoder.Next())
+		tt.AssertNoErr(t, decoder.Decode(&user))
+
+		tt.AssertTrue(t, decoder.Next())
+		err = decoder.Decode(&user)
+		tt.AssertErrContains(t, err, "line 3", "column 13", "score", "not-a-float")
+
+		var csvErr ss.CSVError
+		tt.AssertTrue(t, errors.As(err, &csvErr), "error %#v should wrap a CSVError", err)
+		tt.AssertEqual(t, csvErr.Line, 3)
+		tt.AssertEqual(t, csvErr.Column, 13)
+	})
+
+	t.Run("should report malformed csv files through Err()", func(t *testing.T) {
+		decoder, err := ss.NewCSVDecoder(csv.NewReader(strings.NewReader(
+			"id,name,score,active\n" +
+				"1,\"fakeName1,4.5,true\n",
+		)))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, decoder.Next(), false)
+		tt.AssertErrContains(t, decoder.Err(), "line 2")
+	})
+
+	t.Run("should report error for invalid column indexes", func(t *testing.T) {
+		decoder, err := ss.NewCSVDecoder(csv.NewReader(strings.NewReader(
+			"id\n" +
+				"1\n",
+		)))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertTrue(t, decoder.Next())
+		var output struct {
+			ID int `csv:"#notAnIndex"`
+		}
+		err = decoder.Decode(&output)
+		tt.AssertErrContains(t, err, "invalid csv column index", "#notAnIndex")
+	})
+
+	t.Run("should report error if Decode is called before Next", func(t *testing.T) {
+		decoder, err := ss.NewCSVDecoder(csv.NewReader(strings.NewReader(
+			"id\n" +
+				"1\n",
+		)))
+		tt.AssertNoErr(t, err)
+
+		var user User
+		err = decoder.Decode(&user)
+		tt.AssertErrContains(t, err, "Next()")
+	})
+
+	t.Run("should report error if the header cannot be read", func(t *testing.T) {
+		_, err := ss.NewCSVDecoder(csv.NewReader(strings.NewReader("")))
+		tt.AssertErrContains(t, err, "csv header")
+	})
+}
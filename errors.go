@@ -0,0 +1,110 @@
+package structscanner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError is returned by Decode when it fails to decode a specific field,
+// or a nested struct, in which case the Path refers to the nested struct.
+type FieldError struct {
+	// Path is the path of the field from the root struct, e.g. "DB.Port"
+	Path string
+
+	// Value is the raw value returned by the decoder, if any, it
+	// is replaced by RedactedValue if the field is a secret.
+	Value interface{}
+
+	// IsSecret is true if the field is a secret, in which case
+	// the message of the Err is omitted from the error message.
+	IsSecret bool
+
+	Err error
+}
+
+func newFieldError(path string, field Field, value interface{}, err error) *FieldError {
+	if field.IsSecret && value != nil {
+		value = RedactedValue
+	}
+
+	return &FieldError{
+		Path:     path,
+		Value:    value,
+		IsSecret: field.IsSecret,
+		Err:      err,
+	}
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		// Errors on the root struct itself, e.g. errors from a Validate() method:
+		return fmt.Sprintf("error decoding struct: %s", e.Err)
+	}
+
+	if e.IsSecret {
+		// The message of the wrapped error might contain the secret value,
+		// so we only display its type, the error itself is still
+		// available through errors.Unwrap() for programmatic use:
+		return fmt.Sprintf("error decoding secret field %s: %T (message redacted)", e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("error decoding field %s: %s", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// RedactedSecretError is returned by Decode for secret fields whose value
+// is RedactedValue, e.g. when decoding a map returned by ToMap without
+// the `IncludeSecrets()` option, instead of writing it into the field.
+type RedactedSecretError struct{}
+
+func (RedactedSecretError) Error() string {
+	return fmt.Sprintf("the value of the secret is %q, was it encoded by ToMap without the IncludeSecrets() option?", RedactedValue)
+}
+
+// FieldErrors is used for reporting several field errors at once,
+// e.g. when more than one field fails validation.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the field errors, which on Go 1.20 or newer
+// allows errors.Is and errors.As to match any of them.
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Is allows errors.Is to match any of the field errors
+// on versions of Go that ignore the Unwrap() []error method.
+func (e FieldErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As allows errors.As to match any of the field errors
+// on versions of Go that ignore the Unwrap() []error method.
+func (e FieldErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package structscanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// FileTagDecoder wraps another decoder so that the values of some fields
+// are read from files, which is how Docker and Kubernetes secrets are
+// usually mounted, e.g.:
+//
+//	type Config struct {
+//		DBPassword string `env:"DB_PASSWORD"`
+//		TLSCert    string `env:"TLS_CERT_PATH" file:"true"`
+//	}
+//
+// A field is read from a file if:
+//
+//   - The `<key>_FILE` variant of its key is provided, e.g. `DB_PASSWORD_FILE=/run/secrets/db`,
+//     in which case it takes precedence over the value of the key itself.
+//   - It has the `file:"true"` tag, in which case the value of the key is the path of the file.
+//
+// A single trailing newline is removed from the contents of the file.
+type FileTagDecoder struct {
+	tagName string
+	decoder TagDecoder
+	fsys    fs.FS
+}
+
+// NewFileTagDecoder returns a new FileTagDecoder that wraps the given
+// decoder, the tagName is used for building the `<key>_FILE` variants.
+//
+// Files are read from the OS filesystem, use WithFS for reading
+// them from a different file system, e.g. a fstest.MapFS on tests.
+func NewFileTagDecoder(tagName string, decoder TagDecoder) FileTagDecoder {
+	return FileTagDecoder{
+		tagName: tagName,
+		decoder: decoder,
+	}
+}
+
+// WithFS returns a copy of the decoder that reads the files from fsys.
+//
+// Since fs.FS paths are unrooted any leading "/" is removed
+// from the paths, so "/run/secrets/db" is read as "run/secrets/db".
+func (f FileTagDecoder) WithFS(fsys fs.FS) FileTagDecoder {
+	f.fsys = fsys
+	return f
+}
+
+// DecodeField implements the TagDecoder interface
+func (f FileTagDecoder) DecodeField(info Field) (interface{}, error) {
+	return f.DecodeFieldContext(context.Background(), info)
+}
+
+// DecodeFieldContext implements the ContextTagDecoder interface
+func (f FileTagDecoder) DecodeFieldContext(ctx context.Context, info Field) (interface{}, error) {
+	value, _, err := f.decodeFieldWithSource(ctx, info)
+	return value, err
+}
+
+// decodeFieldWithSource reports the wrapped decoder as the
+// source of the values, since it is the one providing the paths.
+func (f FileTagDecoder) decodeFieldWithSource(ctx context.Context, info Field) (interface{}, TagDecoder, error) {
+	if hasNestedValues(info) {
+		value, source, err := decodeFieldWithSource(ctx, f.decoder, info)
+		if err != nil {
+			return nil, nil, err
+		}
+		return wrapNested(value, func(nested TagDecoder) TagDecoder {
+			f.decoder = nested
+			return f
+		}), source, nil
+	}
+
+	key := info.Key(f.tagName)
+	if key == "" {
+		return decodeFieldWithSource(ctx, f.decoder, info)
+	}
+
+	path, source, err := f.decodePath(ctx, info, key+"_FILE")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if path != "" {
+		value, err := f.readFile(info, path)
+		return value, source, err
+	}
+
+	if info.Tags["file"] != "true" {
+		return decodeFieldWithSource(ctx, f.decoder, info)
+	}
+
+	path, source, err = f.decodePath(ctx, info, key)
+	if err != nil || path == "" {
+		return nil, source, err
+	}
+
+	value, err := f.readFile(info, path)
+	return value, source, err
+}
+
+// UnusedKeys implements the UnusedKeysReporter interface
+// by forwarding the unused keys of the wrapped decoder.
+func (f FileTagDecoder) UnusedKeys() []string {
+	keys, _ := unusedKeys(f.decoder)
+	return keys
+}
+
+// markKeyUsed implements the keyMarker interface by marking
+// both the key and its `<key>_FILE` variant without reading files.
+func (f FileTagDecoder) markKeyUsed(ctx context.Context, info Field) {
+	markKeyUsed(ctx, f.decoder, info)
+	if key := info.Key(f.tagName); key != "" {
+		markKeyUsed(ctx, f.decoder, stringKeyField(info, f.tagName, key+"_FILE"))
+	}
+}
+
+var stringType = reflect.TypeOf("")
+
+// decodePath asks the wrapped decoder for the value of the given key
+// as if it was a string field, so decoders that parse their values
+// by the type of the field don't try to parse the path.
+func (f FileTagDecoder) decodePath(ctx context.Context, info Field, key string) (string, TagDecoder, error) {
+	value, source, err := decodeFieldWithSource(ctx, f.decoder, stringKeyField(info, f.tagName, key))
+	if err != nil || value == nil {
+		return "", source, err
+	}
+
+	path, ok := value.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("expected file path %q to be a string but got: %T", key, value)
+	}
+
+	return path, source, nil
+}
+
+// trimTrailingNewline removes a single trailing "\n" or "\r\n",
+// which most editors add to the end of the files.
+func trimTrailingNewline(s string) string {
+	if strings.HasSuffix(s, "\n") {
+		s = strings.TrimSuffix(s, "\n")
+		s = strings.TrimSuffix(s, "\r")
+	}
+	return s
+}
+
+func (f FileTagDecoder) readFile(info Field, path string) (interface{}, error) {
+	var content []byte
+	var err error
+	if f.fsys != nil {
+		content, err = fs.ReadFile(f.fsys, strings.TrimPrefix(path, "/"))
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading file of field %s: %w", info.Name, err)
+	}
+
+	s := trimTrailingNewline(string(content))
+	if info.Type == bytesType {
+		return []byte(s), nil
+	}
+
+	return parseString(info, s)
+}
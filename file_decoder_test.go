@@ -0,0 +1,141 @@
+package structscanner_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestFileTagDecoder(t *testing.T) {
+	fakeFS := fstest.MapFS{
+		"run/secrets/db_password": {Data: []byte("fakePassword\n")},
+		"run/secrets/db_port":     {Data: []byte("5432\r\n")},
+		"run/secrets/api_key":     {Data: []byte("fakeKey\n\n")},
+		"etc/certs/tls.pem":       {Data: []byte("fakeCert")},
+	}
+
+	t.Run("should read the values from the files", func(t *testing.T) {
+		var config struct {
+			DBPassword ss.Secret[string] `env:"DB_PASSWORD"`
+			DBPort     int               `env:"DB_PORT"`
+			APIKey     []byte            `env:"API_KEY"`
+			TLSCert    string            `env:"TLS_CERT_PATH" file:"true"`
+			LogLevel   string            `env:"LOG_LEVEL"`
+			Nested     struct {
+				Password string `env:"NESTED_PASSWORD"`
+			} `env:"NESTED"`
+		}
+		decoder := ss.NewFileTagDecoder("env", ss.NewStringMapTagDecoder("env", map[string]interface{}{
+			"DB_PASSWORD":      "ignoredPassword",
+			"DB_PASSWORD_FILE": "/run/secrets/db_password",
+			"DB_PORT_FILE":     "/run/secrets/db_port",
+			"API_KEY_FILE":     "run/secrets/api_key",
+			"TLS_CERT_PATH":    "/etc/certs/tls.pem",
+			"LOG_LEVEL":        "info",
+			"NESTED": map[string]interface{}{
+				"NESTED_PASSWORD_FILE": "/run/secrets/db_password",
+			},
+		})).WithFS(fakeFS)
+
+		err := ss.Decode(&config, decoder)
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, config.DBPassword.Value(), "fakePassword")
+		tt.AssertEqual(t, config.DBPort, 5432)
+		tt.AssertEqual(t, config.APIKey, []byte("fakeKey\n"))
+		tt.AssertEqual(t, config.TLSCert, "fakeCert")
+		tt.AssertEqual(t, config.LogLevel, "info")
+		tt.AssertEqual(t, config.Nested.Password, "fakePassword")
+	})
+
+	t.Run("should read from the OS filesystem by default", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "fakeFile")
+		err := os.WriteFile(filePath, []byte("fakeContent\n"), 0o600)
+		tt.AssertNoErr(t, err)
+
+		var config struct {
+			Attr1 string `map:"attr1"`
+		}
+		err = ss.Decode(&config, ss.NewFileTagDecoder("map", ss.NewMapTagDecoder("map", map[string]interface{}{
+			"attr1_FILE": filePath,
+		})))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Attr1, "fakeContent")
+	})
+
+	t.Run("should keep the field untouched if no value is provided", func(t *testing.T) {
+		config := struct {
+			Attr1 string `map:"attr1" file:"true"`
+		}{
+			Attr1: "fakeDefault",
+		}
+		err := ss.Decode(&config, ss.NewFileTagDecoder("map", ss.NewMapTagDecoder("map", map[string]interface{}{})).WithFS(fakeFS))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Attr1, "fakeDefault")
+	})
+
+	t.Run("should report the wrapped decoder as the source of the values", func(t *testing.T) {
+		var config struct {
+			Attr1 string `env:"ATTR1"`
+		}
+		var report ss.DecodeReport
+		err := ss.Decode(&config, ss.NewFileTagDecoder("env", ss.NamedTagDecoder("env", ss.NewMapTagDecoder("env", map[string]interface{}{
+			"ATTR1_FILE": "/etc/certs/tls.pem",
+		}))).WithFS(fakeFS), ss.WithReport(&report))
+		tt.AssertNoErr(t, err)
+
+		fieldReport, found := report.Lookup("Attr1")
+		tt.AssertTrue(t, found)
+		tt.AssertEqual(t, fieldReport.Source, "env")
+	})
+
+	t.Run("should report errors", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			sourceMap          map[string]interface{}
+			targetStruct       interface{}
+			expectErrToContain []string
+		}{
+			{
+				desc: "missing file",
+				sourceMap: map[string]interface{}{
+					"attr1_FILE": "/run/secrets/notAFile",
+				},
+				targetStruct: &struct {
+					Attr1 string `map:"attr1"`
+				}{},
+				expectErrToContain: []string{"Attr1", "notAFile", "not exist"},
+			},
+			{
+				desc: "path is not a string",
+				sourceMap: map[string]interface{}{
+					"attr1": 42,
+				},
+				targetStruct: &struct {
+					Attr1 string `map:"attr1" file:"true"`
+				}{},
+				expectErrToContain: []string{"Attr1", "file path", "attr1", "int"},
+			},
+			{
+				desc: "invalid file contents",
+				sourceMap: map[string]interface{}{
+					"attr1_FILE": "/etc/certs/tls.pem",
+				},
+				targetStruct: &struct {
+					Attr1 int `map:"attr1"`
+				}{},
+				expectErrToContain: []string{"Attr1", "fakeCert"},
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				err := ss.Decode(test.targetStruct, ss.NewFileTagDecoder("map", ss.NewMapTagDecoder("map", test.sourceMap)).WithFS(fakeFS))
+				tt.AssertErrContains(t, err, test.expectErrToContain...)
+			})
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package structscanner
+
+import "fmt"
+
+// BeforeDecoder can be implemented by structs that need to prepare
+// themselves before being decoded, e.g. for setting computed defaults.
+//
+// Decode calls BeforeDecode before decoding any of the fields of the struct.
+type BeforeDecoder interface {
+	BeforeDecode() error
+}
+
+// AfterDecoder can be implemented by structs that need to do some
+// work after being decoded, e.g. for filling derived fields.
+//
+// Decode calls AfterDecode after all the fields of the struct, including
+// nested structs, were decoded, and before calling Validate.
+type AfterDecoder interface {
+	AfterDecode() error
+}
+
+// Validator can be implemented by structs that need validations that
+// can't be expressed with the `validate` tag, e.g. checks that depend on
+// more than one field.
+//
+// Decode calls Validate after AfterDecode and reports its error together
+// with the errors of the `validate` tags.
+type Validator interface {
+	Validate() error
+}
+
+func (d *decodeState) callBeforeDecode(path string, targetStruct interface{}) error {
+	hook, ok := targetStruct.(BeforeDecoder)
+	if !ok {
+		return nil
+	}
+
+	err := hook.BeforeDecode()
+	if err != nil {
+		return &FieldError{
+			Path: path,
+			Err:  fmt.Errorf("error on BeforeDecode: %w", err),
+		}
+	}
+	return nil
+}
+
+func (d *decodeState) callAfterDecode(path string, targetStruct interface{}) error {
+	if hook, ok := targetStruct.(AfterDecoder); ok {
+		err := hook.AfterDecode()
+		if err != nil {
+			return &FieldError{
+				Path: path,
+				Err:  fmt.Errorf("error on AfterDecode: %w", err),
+			}
+		}
+	}
+
+	if validator, ok := targetStruct.(Validator); ok {
+		err := validator.Validate()
+		if err != nil {
+			d.validationErrors = append(d.validationErrors, &FieldError{
+				Path: path,
+				Err:  err,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package structscanner_test
+
+import (
+	"errors"
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+// hooksCalls is used for recording the order in which the hooks are called:
+var hooksCalls []string
+
+type hooksTLSConfig struct {
+	Enabled  bool   `map:"enabled"`
+	CertFile string `map:"cert_file"`
+	Port     int    `map:"port"`
+}
+
+func (c *hooksTLSConfig) BeforeDecode() error {
+	hooksCalls = append(hooksCalls, "TLS.BeforeDecode")
+	c.Port = 443
+	return nil
+}
+
+func (c *hooksTLSConfig) AfterDecode() error {
+	hooksCalls = append(hooksCalls, "TLS.AfterDecode")
+	return nil
+}
+
+func (c hooksTLSConfig) Validate() error {
+	hooksCalls = append(hooksCalls, "TLS.Validate")
+	if c.Enabled && c.CertFile == "" {
+		return errors.New("cert_file is required when TLS is enabled")
+	}
+	return nil
+}
+
+type hooksConfig struct {
+	Host string         `map:"host"`
+	TLS  hooksTLSConfig `map:"tls"`
+
+	Address string
+
+	afterDecodeErr error
+}
+
+func (c *hooksConfig) BeforeDecode() error {
+	hooksCalls = append(hooksCalls, "BeforeDecode")
+	return nil
+}
+
+func (c *hooksConfig) AfterDecode() error {
+	hooksCalls = append(hooksCalls, "AfterDecode")
+	c.Address = c.Host + ":" + "443"
+	return c.afterDecodeErr
+}
+
+func (c *hooksConfig) Validate() error {
+	hooksCalls = append(hooksCalls, "Validate")
+	if c.Host == "" {
+		return errors.New("host is required")
+	}
+	return nil
+}
+
+func TestDecodeHooks(t *testing.T) {
+	t.Run("should call the hooks of all structs bottom up", func(t *testing.T) {
+		hooksCalls = nil
+
+		var config hooksConfig
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"host": "fakeHost",
+			"tls": map[string]interface{}{
+				"enabled":   true,
+				"cert_file": "fakeCertFile",
+			},
+		}))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, hooksCalls, []string{
+			"BeforeDecode",
+			"TLS.BeforeDecode",
+			"TLS.AfterDecode",
+			"TLS.Validate",
+			"AfterDecode",
+			"Validate",
+		})
+		tt.AssertEqual(t, config.TLS.Port, 443)
+		tt.AssertEqual(t, config.Address, "fakeHost:443")
+	})
+
+	t.Run("should call the hooks of nested structs missing on the source", func(t *testing.T) {
+		hooksCalls = nil
+
+		var config hooksConfig
+		err := ss.Decode(&config, ss.ChainTagDecoder(ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+			if field.Name == "Host" {
+				return "fakeHost", nil
+			}
+			return nil, nil
+		})))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, hooksCalls, []string{
+			"BeforeDecode",
+			"TLS.BeforeDecode",
+			"TLS.AfterDecode",
+			"TLS.Validate",
+			"AfterDecode",
+			"Validate",
+		})
+		tt.AssertEqual(t, config.TLS.Port, 443)
+	})
+
+	t.Run("should report the errors of all Validate methods with their paths", func(t *testing.T) {
+		var config hooksConfig
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"tls": map[string]interface{}{
+				"enabled": true,
+			},
+		}))
+		tt.AssertErrContains(t, err,
+			"TLS", "cert_file is required when TLS is enabled",
+			"error decoding struct", "host is required",
+		)
+
+		var fieldErrs ss.FieldErrors
+		tt.AssertTrue(t, errors.As(err, &fieldErrs), "error %#v should be a FieldErrors", err)
+		tt.AssertEqual(t, len(fieldErrs), 2)
+		tt.AssertEqual(t, fieldErrs[0].Path, "TLS")
+		tt.AssertEqual(t, fieldErrs[1].Path, "")
+	})
+
+	t.Run("should report errors from AfterDecode", func(t *testing.T) {
+		fakeErr := errors.New("fakeErrMsg")
+
+		config := hooksConfig{
+			afterDecodeErr: fakeErr,
+		}
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"host": "fakeHost",
+			"tls":  map[string]interface{}{},
+		}))
+		tt.AssertErrContains(t, err, "AfterDecode", "fakeErrMsg")
+		tt.AssertTrue(t, errors.Is(err, fakeErr), "error %#v should wrap %#v", err, fakeErr)
+	})
+
+	t.Run("should report errors from BeforeDecode with the path of the struct", func(t *testing.T) {
+		var output struct {
+			Nested hooksBeforeDecodeErr `map:"nested"`
+		}
+		err := ss.Decode(&output, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"nested": map[string]interface{}{},
+		}))
+		tt.AssertErrContains(t, err, "Nested", "BeforeDecode", "fakeBeforeDecodeErr")
+
+		var fieldErr *ss.FieldError
+		tt.AssertTrue(t, errors.As(err, &fieldErr), "error %#v should be a FieldError", err)
+		tt.AssertEqual(t, fieldErr.Path, "Nested")
+	})
+}
+
+type hooksBeforeDecodeErr struct{}
+
+func (hooksBeforeDecodeErr) BeforeDecode() error {
+	return errors.New("fakeBeforeDecodeErr")
+}
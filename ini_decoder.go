@@ -0,0 +1,314 @@
+package structscanner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError is returned when an INI or properties file is malformed.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error on line %d: %s", e.Line, e.Msg)
+}
+
+// NewINITagDecoder parses an INI file and returns a decoder for
+// filling a struct with its values.
+//
+// Keys outside of any section are mapped to the fields of the struct
+// and each section is mapped to a nested struct, dots in the section
+// names can be used to reach deeper nested structs, e.g. `[server.http]`.
+//
+// Lines starting with `;` or `#` are comments, and so is the rest of a line
+// after a `;` or `#` preceded by a whitespace, e.g. `port = 80 ; default`,
+// unless it is inside double quotes. Values can be surrounded by double
+// quotes and a trailing `\` continues the value on the next line.
+func NewINITagDecoder(tagName string, reader io.Reader) (StringMapTagDecoder, error) {
+	values, err := parseINI(reader)
+	if err != nil {
+		return StringMapTagDecoder{}, err
+	}
+
+	return NewStringMapTagDecoder(tagName, values), nil
+}
+
+// NewPropertiesTagDecoder parses a Java properties file and returns a
+// decoder for filling a struct with its values.
+//
+// Dotted keys are mapped to nested structs, e.g. `db.host` is mapped to the
+// field tagged with `host` inside of the nested struct tagged with `db`.
+//
+// Lines starting with `#` or `!` are comments and a trailing `\`
+// continues the value on the next line.
+func NewPropertiesTagDecoder(tagName string, reader io.Reader) (StringMapTagDecoder, error) {
+	values, err := parseProperties(reader)
+	if err != nil {
+		return StringMapTagDecoder{}, err
+	}
+
+	return NewStringMapTagDecoder(tagName, values), nil
+}
+
+func parseINI(reader io.Reader) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	section := root
+	err := readLogicalLines(reader, ";#", func(lineNumber int, line string) error {
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return SyntaxError{Line: lineNumber, Msg: "missing closing bracket on section header"}
+			}
+
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return SyntaxError{Line: lineNumber, Msg: "empty section name"}
+			}
+
+			var err error
+			section, err = getOrCreateSection(root, strings.Split(name, "."), lineNumber)
+			return err
+		}
+
+		sepIdx := strings.IndexAny(line, "=:")
+		if sepIdx < 0 {
+			return SyntaxError{Line: lineNumber, Msg: fmt.Sprintf("expected key=value but got: %q", line)}
+		}
+
+		key := strings.TrimSpace(line[:sepIdx])
+		if key == "" {
+			return SyntaxError{Line: lineNumber, Msg: "missing key before separator"}
+		}
+
+		value := strings.TrimSpace(stripInlineComment(line[sepIdx+1:]))
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+
+		value, err := unescape(value, lineNumber)
+		if err != nil {
+			return err
+		}
+
+		return setValue(section, []string{key}, value, lineNumber)
+	})
+
+	return root, err
+}
+
+func parseProperties(reader io.Reader) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	err := readLogicalLines(reader, "#!", func(lineNumber int, line string) error {
+		// The key ends at the first unescaped separator,
+		// which can be either `=`, `:` or a whitespace:
+		keyEnd := len(line)
+		for i := 0; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+			if strings.IndexByte("=: \t", line[i]) >= 0 {
+				keyEnd = i
+				break
+			}
+		}
+
+		rest := strings.TrimLeft(line[keyEnd:], " \t")
+		if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+			rest = strings.TrimLeft(rest[1:], " \t")
+		}
+
+		value, err := unescape(rest, lineNumber)
+		if err != nil {
+			return err
+		}
+
+		// Escaped dots, e.g. `app\.name`, are not used for nesting:
+		key := line[:keyEnd]
+		path := splitUnescaped(key, '.')
+		for i := range path {
+			path[i], err = unescape(path[i], lineNumber)
+			if err != nil {
+				return err
+			}
+			if path[i] == "" {
+				return SyntaxError{Line: lineNumber, Msg: fmt.Sprintf("invalid key: %q", key)}
+			}
+		}
+
+		return setValue(root, path, value, lineNumber)
+	})
+
+	return root, err
+}
+
+// readLogicalLines calls fn for each non empty and non comment line of the
+// input, joining lines that end with a backslash with the line that follows.
+func readLogicalLines(reader io.Reader, commentChars string, fn func(lineNumber int, line string) error) error {
+	scanner := bufio.NewScanner(reader)
+
+	lineNumber := 0
+	startLine := 0
+	var logicalLine strings.Builder
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if logicalLine.Len() == 0 {
+			if line == "" || strings.IndexByte(commentChars, line[0]) >= 0 {
+				continue
+			}
+			startLine = lineNumber
+		}
+
+		if hasContinuation(line) {
+			logicalLine.WriteString(line[:len(line)-1])
+			continue
+		}
+
+		logicalLine.WriteString(line)
+		err := fn(startLine, logicalLine.String())
+		if err != nil {
+			return err
+		}
+		logicalLine.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if logicalLine.Len() > 0 {
+		return fn(startLine, logicalLine.String())
+	}
+
+	return nil
+}
+
+func splitUnescaped(s string, sep byte) []string {
+	parts := []string{}
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// hasContinuation checks if the line ends with an odd number of
+// backslashes, since an even number means the last one is escaped.
+func hasContinuation(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// stripInlineComment removes the comment at the end of an INI value,
+// which starts with a `;` or `#` preceded by a whitespace and
+// outside of double quotes, escaped characters are ignored.
+func stripInlineComment(value string) string {
+	inQuotes := false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\':
+			i++
+		case c == '"':
+			inQuotes = !inQuotes
+		case (c == ';' || c == '#') && !inQuotes && i > 0 && (value[i-1] == ' ' || value[i-1] == '\t'):
+			return value[:i]
+		}
+	}
+	return value
+}
+
+func unescape(s string, lineNumber int) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'u':
+			if i+5 > len(s) {
+				return "", SyntaxError{Line: lineNumber, Msg: fmt.Sprintf("incomplete unicode escape on: %q", s)}
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", SyntaxError{Line: lineNumber, Msg: fmt.Sprintf("invalid unicode escape on: %q", s)}
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			// Any other escaped character, e.g. `\=` or `\\`,
+			// is written as is:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+func getOrCreateSection(root map[string]interface{}, path []string, lineNumber int) (map[string]interface{}, error) {
+	section := root
+	for _, name := range path {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, SyntaxError{Line: lineNumber, Msg: "empty section name"}
+		}
+
+		value, found := section[name]
+		if !found {
+			nested := map[string]interface{}{}
+			section[name] = nested
+			section = nested
+			continue
+		}
+
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, SyntaxError{Line: lineNumber, Msg: fmt.Sprintf("section %q conflicts with a key of the same name", name)}
+		}
+		section = nested
+	}
+
+	return section, nil
+}
+
+func setValue(root map[string]interface{}, path []string, value string, lineNumber int) error {
+	section, err := getOrCreateSection(root, path[:len(path)-1], lineNumber)
+	if err != nil {
+		return err
+	}
+
+	key := path[len(path)-1]
+	if _, isSection := section[key].(map[string]interface{}); isSection {
+		return SyntaxError{Line: lineNumber, Msg: fmt.Sprintf("key %q conflicts with a section of the same name", key)}
+	}
+
+	section[key] = value
+	return nil
+}
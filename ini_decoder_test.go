@@ -0,0 +1,223 @@
+package structscanner_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestINITagDecoder(t *testing.T) {
+	type Config struct {
+		Name     string `ini:"name"`
+		Database struct {
+			Host    string        `ini:"host"`
+			Port    int           `ini:"port"`
+			Timeout time.Duration `ini:"timeout"`
+		} `ini:"database"`
+		Server *struct {
+			HTTP struct {
+				Port    uint16 `ini:"port"`
+				Enabled bool   `ini:"enabled"`
+			} `ini:"http"`
+		} `ini:"server"`
+		Motd string `ini:"motd"`
+	}
+
+	t.Run("should decode sections into nested structs", func(t *testing.T) {
+		decoder, err := ss.NewINITagDecoder("ini", strings.NewReader(`
+			; This is a comment
+			# and so is this
+			name = fakeName
+			motd = "  first line \
+			  second line\tafter tab"
+
+			[database]
+			host: localhost
+			port = 5432
+			timeout = 5s
+
+			[server.http]
+			port = 8080
+			enabled = true
+		`))
+		tt.AssertNoErr(t, err)
+
+		var config Config
+		err = ss.Decode(&config, decoder)
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, config.Name, "fakeName")
+		tt.AssertEqual(t, config.Motd, "  first line second line\tafter tab")
+		tt.AssertEqual(t, config.Database.Host, "localhost")
+		tt.AssertEqual(t, config.Database.Port, 5432)
+		tt.AssertEqual(t, config.Database.Timeout, 5*time.Second)
+		tt.AssertNotEqual(t, config.Server, nil)
+		tt.AssertEqual(t, config.Server.HTTP.Port, uint16(8080))
+		tt.AssertEqual(t, config.Server.HTTP.Enabled, true)
+	})
+
+	t.Run("should strip inline comments", func(t *testing.T) {
+		decoder, err := ss.NewINITagDecoder("ini", strings.NewReader(`
+			name = fakeName ; the name
+			motd = "semicolons ; and # hashes" # inside quotes are kept
+
+			[database]
+			host = db#1;a	# only the hash after the tab starts a comment
+			port = 3 # the port
+			timeout = 5s ;
+		`))
+		tt.AssertNoErr(t, err)
+
+		var config Config
+		err = ss.Decode(&config, decoder)
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, config.Name, "fakeName")
+		tt.AssertEqual(t, config.Motd, "semicolons ; and # hashes")
+		tt.AssertEqual(t, config.Database.Host, "db#1;a")
+		tt.AssertEqual(t, config.Database.Port, 3)
+		tt.AssertEqual(t, config.Database.Timeout, 5*time.Second)
+	})
+
+	t.Run("should report syntax errors with line numbers", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			ini                string
+			expectErrToContain []string
+		}{
+			{
+				desc:               "missing closing bracket",
+				ini:                "name = foo\n[database\nhost = bar",
+				expectErrToContain: []string{"line 2", "closing bracket"},
+			},
+			{
+				desc:               "empty section",
+				ini:                "[ ]",
+				expectErrToContain: []string{"line 1", "empty section name"},
+			},
+			{
+				desc:               "missing separator",
+				ini:                "\n\nname foo",
+				expectErrToContain: []string{"line 3", "expected key=value", "name foo"},
+			},
+			{
+				desc:               "missing key",
+				ini:                "= foo",
+				expectErrToContain: []string{"line 1", "missing key"},
+			},
+			{
+				desc:               "invalid unicode escape",
+				ini:                `name = \uZZZZ`,
+				expectErrToContain: []string{"line 1", "invalid unicode escape"},
+			},
+			{
+				desc:               "section conflicting with key",
+				ini:                "database = foo\n[database]",
+				expectErrToContain: []string{"line 2", "database", "conflicts"},
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				_, err := ss.NewINITagDecoder("ini", strings.NewReader(test.ini))
+				tt.AssertErrContains(t, err, test.expectErrToContain...)
+
+				var syntaxErr ss.SyntaxError
+				tt.AssertTrue(t, errors.As(err, &syntaxErr), "error %#v should be a SyntaxError", err)
+			})
+		}
+	})
+
+	t.Run("should report conversion errors", func(t *testing.T) {
+		decoder, err := ss.NewINITagDecoder("ini", strings.NewReader(`
+			[database]
+			port = notANumber
+		`))
+		tt.AssertNoErr(t, err)
+
+		var config Config
+		err = ss.Decode(&config, decoder)
+		tt.AssertErrContains(t, err, "Database", "Port", "notANumber")
+	})
+
+	t.Run("should report error when a section is mapped into a non struct field", func(t *testing.T) {
+		decoder, err := ss.NewINITagDecoder("ini", strings.NewReader(`
+			[name]
+			foo = bar
+		`))
+		tt.AssertNoErr(t, err)
+
+		var config Config
+		err = ss.Decode(&config, decoder)
+		tt.AssertErrContains(t, err, "nested map", "name", "Name", "string")
+	})
+}
+
+func TestPropertiesTagDecoder(t *testing.T) {
+	type Config struct {
+		Name string `properties:"app.name"`
+		Path string `properties:"path"`
+		DB   struct {
+			Host string `properties:"host"`
+			Port int    `properties:"port"`
+		} `properties:"db"`
+		Greeting string `properties:"greeting"`
+	}
+
+	t.Run("should decode dotted keys into nested structs", func(t *testing.T) {
+		decoder, err := ss.NewPropertiesTagDecoder("properties", strings.NewReader(`
+			# comment
+			! also a comment
+			db.host = localhost
+			db.port: 5432
+			path c:\\windows\\path
+			greeting = hello \
+			           world \u00e9
+			app\.name = fakeName
+		`))
+		tt.AssertNoErr(t, err)
+
+		var config Config
+		err = ss.Decode(&config, decoder)
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, config.DB.Host, "localhost")
+		tt.AssertEqual(t, config.DB.Port, 5432)
+		tt.AssertEqual(t, config.Path, `c:\windows\path`)
+		tt.AssertEqual(t, config.Greeting, "hello world é")
+		tt.AssertEqual(t, config.Name, "fakeName")
+	})
+
+	t.Run("should report syntax errors with line numbers", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			properties         string
+			expectErrToContain []string
+		}{
+			{
+				desc:               "empty key segment",
+				properties:         "db..host = foo",
+				expectErrToContain: []string{"line 1", "invalid key", "db..host"},
+			},
+			{
+				desc:               "incomplete unicode escape",
+				properties:         "\nname = \\u00",
+				expectErrToContain: []string{"line 2", "incomplete unicode escape"},
+			},
+			{
+				desc:               "key conflicting with nested keys",
+				properties:         "db.host = foo\ndb = bar",
+				expectErrToContain: []string{"line 2", "db", "conflicts"},
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				_, err := ss.NewPropertiesTagDecoder("properties", strings.NewReader(test.properties))
+				tt.AssertErrContains(t, err, test.expectErrToContain...)
+			})
+		}
+	})
+}
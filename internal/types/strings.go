@@ -3,9 +3,26 @@ package types
 import (
 	"reflect"
 	"strconv"
+	"time"
 )
 
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// StringToType parses the input string into a value of the kind of the
+// type t, if t is a pointer the kind of the pointed type is used instead.
+//
+// Types whose kind is not handled here are returned as strings
+// so that the Converter can attempt to convert them later.
 func StringToType(t reflect.Type, v string) (reflect.Value, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		d, err := time.ParseDuration(v)
+		return reflect.ValueOf(d), err
+	}
+
 	switch t.Kind() {
 	case reflect.Int:
 		i, err := strconv.Atoi(v)
@@ -24,7 +41,7 @@ func StringToType(t reflect.Type, v string) (reflect.Value, error) {
 		return reflect.ValueOf(int64(i)), err
 
 	case reflect.Uint:
-		i, err := strconv.Atoi(v)
+		i, err := strconv.ParseUint(v, 10, 0)
 		return reflect.ValueOf(uint(i)), err
 	case reflect.Uint8:
 		i, err := strconv.ParseUint(v, 10, 8)
@@ -38,6 +55,17 @@ func StringToType(t reflect.Type, v string) (reflect.Value, error) {
 	case reflect.Uint64:
 		i, err := strconv.ParseUint(v, 10, 64)
 		return reflect.ValueOf(uint64(i)), err
+
+	case reflect.Float32:
+		f, err := strconv.ParseFloat(v, 32)
+		return reflect.ValueOf(float32(f)), err
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(v, 64)
+		return reflect.ValueOf(f), err
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(v)
+		return reflect.ValueOf(b), err
 	}
 
 	return reflect.ValueOf(v), nil
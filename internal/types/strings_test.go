@@ -0,0 +1,87 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestStringToType(t *testing.T) {
+	tests := []struct {
+		desc               string
+		input              string
+		targetType         reflect.Type
+		expectedOutput     any
+		expectErrToContain []string
+	}{
+		{
+			desc:           "should parse ints",
+			input:          "-42",
+			targetType:     reflect.TypeOf(0),
+			expectedOutput: -42,
+		},
+		{
+			desc:           "should parse uints",
+			input:          "42",
+			targetType:     reflect.TypeOf(uint(0)),
+			expectedOutput: uint(42),
+		},
+		{
+			desc:           "should parse floats",
+			input:          "4.2",
+			targetType:     reflect.TypeOf(0.0),
+			expectedOutput: 4.2,
+		},
+		{
+			desc:           "should parse bools",
+			input:          "true",
+			targetType:     reflect.TypeOf(false),
+			expectedOutput: true,
+		},
+		{
+			desc:           "should parse durations",
+			input:          "1m30s",
+			targetType:     reflect.TypeOf(time.Duration(0)),
+			expectedOutput: 90 * time.Second,
+		},
+		{
+			desc:           "should parse into the pointed type for pointers",
+			input:          "42",
+			targetType:     reflect.TypeOf(new(int64)),
+			expectedOutput: int64(42),
+		},
+		{
+			desc:           "should return strings for unhandled kinds",
+			input:          "fakeValue",
+			targetType:     reflect.TypeOf(""),
+			expectedOutput: "fakeValue",
+		},
+		{
+			desc:               "should report parsing errors",
+			input:              "not-a-number",
+			targetType:         reflect.TypeOf(0),
+			expectErrToContain: []string{"not-a-number", "invalid syntax"},
+		},
+		{
+			desc:               "should report out of range errors",
+			input:              "256",
+			targetType:         reflect.TypeOf(uint8(0)),
+			expectErrToContain: []string{"256", "out of range"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			v, err := StringToType(test.targetType, test.input)
+			if test.expectErrToContain != nil {
+				tt.AssertErrContains(t, err, test.expectErrToContain...)
+				t.Skip()
+			}
+
+			tt.AssertNoErr(t, err)
+			tt.AssertEqual(t, v.Interface(), test.expectedOutput)
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package structscanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// InterpolateTagDecoder wraps another decoder in order to resolve
+// `${VAR}` expressions on its string values, e.g.:
+//
+//	type Config struct {
+//		DBHost string `env:"DB_HOST"`
+//		DBPort int    `env:"DB_PORT"`
+//		DBURL  string `env:"DB_URL"` // e.g. "postgres://${DB_HOST}:${DB_PORT:-5432}"
+//	}
+//
+// Each variable is resolved by looking, in order, for:
+//
+//  1. A key with the same name on the same struct, then on its parent structs.
+//  2. A key with the same name on the vars passed to WithVars.
+//  3. An environment variable with the same name.
+//
+// The `${VAR:-default}` syntax can be used for variables that might not
+// be defined or might be empty, the default value can also contain
+// `${...}` expressions, and `$${` can be used for a literal `${`.
+//
+// Referencing an undefined variable without a default
+// or a variable that references itself returns an error.
+type InterpolateTagDecoder struct {
+	tagName string
+	decoder TagDecoder
+	vars    map[string]string
+
+	// parents holds the decoders of the parent structs, from the
+	// closest to the farthest, for resolving references to their keys:
+	parents []TagDecoder
+}
+
+// NewInterpolateTagDecoder returns a new InterpolateTagDecoder
+// that wraps the given decoder, the tagName is used for finding
+// the fields referenced by the `${...}` expressions.
+func NewInterpolateTagDecoder(tagName string, decoder TagDecoder) InterpolateTagDecoder {
+	return InterpolateTagDecoder{
+		tagName: tagName,
+		decoder: decoder,
+	}
+}
+
+// WithVars returns a copy of the decoder that also resolves
+// variables using the given map, which takes precedence
+// over the environment variables.
+func (i InterpolateTagDecoder) WithVars(vars map[string]string) InterpolateTagDecoder {
+	i.vars = vars
+	return i
+}
+
+// DecodeField implements the TagDecoder interface
+func (i InterpolateTagDecoder) DecodeField(info Field) (interface{}, error) {
+	return i.DecodeFieldContext(context.Background(), info)
+}
+
+// DecodeFieldContext implements the ContextTagDecoder interface
+func (i InterpolateTagDecoder) DecodeFieldContext(ctx context.Context, info Field) (interface{}, error) {
+	value, _, err := i.decodeFieldWithSource(ctx, info)
+	return value, err
+}
+
+func (i InterpolateTagDecoder) decodeFieldWithSource(ctx context.Context, info Field) (interface{}, TagDecoder, error) {
+	if hasNestedValues(info) {
+		value, source, err := decodeFieldWithSource(ctx, i.decoder, info)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err = i.wrapNested(ctx, value)
+		return value, source, err
+	}
+
+	// Decoders that parse their values by the type of the field would
+	// fail to parse expressions like "${PORT}" for int fields, so we
+	// first read the value as a string:
+	key := info.Key(i.tagName)
+	if key == "" {
+		return decodeFieldWithSource(ctx, i.decoder, info)
+	}
+
+	rawValue, source, err := i.lookupKey(ctx, i.decoder, info, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rawValue == nil {
+		return nil, source, nil
+	}
+
+	s, ok := rawValue.(string)
+	if !ok {
+		// Values that are not strings have no expressions, but the decoder
+		// is asked again since it might parse them by the type of the field:
+		return decodeFieldWithSource(ctx, i.decoder, info)
+	}
+
+	if strings.Contains(s, "${") {
+		r := resolver{
+			InterpolateTagDecoder: i,
+			ctx:                   ctx,
+			field:                 info,
+		}
+		s, err = r.interpolate(s, []string{key})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error interpolating field %s: %w", info.Name, err)
+		}
+	}
+
+	switch {
+	case info.Type == bytesType:
+		return []byte(s), source, nil
+	case info.hasConverter:
+		return s, source, nil
+	}
+
+	value, err := parseString(info, s)
+	return value, source, err
+}
+
+// UnusedKeys implements the UnusedKeysReporter interface
+// by forwarding the unused keys of the wrapped decoder.
+func (i InterpolateTagDecoder) UnusedKeys() []string {
+	keys, _ := unusedKeys(i.decoder)
+	return keys
+}
+
+// markKeyUsed implements the keyMarker interface
+func (i InterpolateTagDecoder) markKeyUsed(ctx context.Context, info Field) {
+	markKeyUsed(ctx, i.decoder, info)
+}
+
+// lookupKey asks the decoder for the value of the given key as
+// if it was a string field, so decoders that parse their values
+// by the type of the field don't try to parse the expressions.
+func (i InterpolateTagDecoder) lookupKey(ctx context.Context, decoder TagDecoder, info Field, key string) (interface{}, TagDecoder, error) {
+	return decodeFieldWithSource(ctx, decoder, stringKeyField(info, i.tagName, key))
+}
+
+func (i InterpolateTagDecoder) wrapNested(ctx context.Context, value interface{}) (interface{}, error) {
+	if items, ok := value.([]string); ok {
+		r := resolver{
+			InterpolateTagDecoder: i,
+			ctx:                   ctx,
+		}
+		results := make([]string, len(items))
+		for idx, item := range items {
+			var err error
+			results[idx], err = r.interpolate(item, nil)
+			if err != nil {
+				return nil, fmt.Errorf("error interpolating item %d: %w", idx, err)
+			}
+		}
+		return results, nil
+	}
+
+	return wrapNested(value, func(nested TagDecoder) TagDecoder {
+		wrapped := i
+		wrapped.decoder = nested
+		wrapped.parents = append([]TagDecoder{i.decoder}, i.parents...)
+		return wrapped
+	}), nil
+}
+
+// resolver holds the state of the interpolation of a single field.
+type resolver struct {
+	InterpolateTagDecoder
+
+	ctx   context.Context
+	field Field
+}
+
+// interpolate replaces all the `${...}` expressions on s,
+// the stack contains the variables being currently resolved
+// and it is used for detecting cycles.
+func (r resolver) interpolate(s string, stack []string) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start == -1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		// `$${` is an escaped `${`:
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := findClosingBrace(s, start+2)
+		if end == -1 {
+			return "", fmt.Errorf("missing closing brace on expression: %q", s[start:])
+		}
+
+		b.WriteString(s[:start])
+
+		value, err := r.resolveExpression(s[start+2:end], stack)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+
+		s = s[end+1:]
+	}
+}
+
+func (r resolver) resolveExpression(expr string, stack []string) (string, error) {
+	name, defaultValue, hasDefault := strings.Cut(expr, ":-")
+	if name == "" {
+		return "", fmt.Errorf("missing variable name on expression: %q", "${"+expr+"}")
+	}
+
+	for idx, previous := range stack {
+		if previous == name {
+			cycle := append(stack[idx:len(stack):len(stack)], name)
+			return "", fmt.Errorf("cycle detected on variables: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	value, found, err := r.lookupVar(name, append(stack[:len(stack):len(stack)], name))
+	if err != nil {
+		return "", err
+	}
+
+	if value == "" && hasDefault {
+		return r.interpolate(defaultValue, stack)
+	}
+
+	if !found {
+		return "", fmt.Errorf("undefined variable: %q", name)
+	}
+
+	return value, nil
+}
+
+func (r resolver) lookupVar(name string, stack []string) (string, bool, error) {
+	scopes := append([]TagDecoder{r.decoder}, r.parents...)
+	for _, scope := range scopes {
+		value, _, err := r.lookupKey(r.ctx, scope, r.field, name)
+		if err != nil {
+			return "", false, fmt.Errorf("error reading variable %q: %w", name, err)
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if _, isDecoder := value.(TagDecoder); isDecoder {
+			return "", false, fmt.Errorf("can't use nested struct %q as a variable", name)
+		}
+
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Sprint(value), true, nil
+		}
+
+		s, err = r.interpolate(s, stack)
+		return s, true, err
+	}
+
+	if value, found := r.vars[name]; found {
+		return value, true, nil
+	}
+
+	value, found := os.LookupEnv(name)
+	return value, found, nil
+}
+
+// findClosingBrace returns the index of the brace that closes
+// the expression starting at s[start], allowing nested expressions
+// on default values, e.g. "${A:-${B}}".
+func findClosingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
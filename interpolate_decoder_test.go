@@ -0,0 +1,168 @@
+package structscanner_test
+
+import (
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestInterpolateTagDecoder(t *testing.T) {
+	t.Run("should resolve expressions using fields, vars and env variables", func(t *testing.T) {
+		t.Setenv("FAKE_ENV_USER", "fakeEnvUser")
+		t.Setenv("FAKE_EMPTY_ENV", "")
+
+		var config struct {
+			Host    string   `env:"DB_HOST"`
+			Port    int      `env:"DB_PORT"`
+			URL     string   `env:"DB_URL"`
+			Timeout int      `env:"TIMEOUT"`
+			Name    []byte   `env:"NAME"`
+			Escaped string   `env:"ESCAPED"`
+			Hosts   []string `env:"HOSTS"`
+			Nested  struct {
+				Address string `env:"ADDRESS"`
+				Region  string `env:"REGION"`
+			} `env:"NESTED"`
+		}
+		decoder := ss.NewInterpolateTagDecoder("env", ss.NewStringMapTagDecoder("env", map[string]interface{}{
+			"DB_HOST": "localhost",
+			"DB_PORT": "${FAKE_PORT}",
+			"DB_URL":  "postgres://${FAKE_ENV_USER}@${DB_HOST}:${DB_PORT}/${DB_NAME:-${FAKE_EMPTY_ENV:-fakeDB}}",
+			"TIMEOUT": "${UNDEFINED_TIMEOUT:-30}",
+			"NAME":    "${FAKE_NAME}",
+			"ESCAPED": "$${DB_HOST} is ${DB_HOST}",
+			"HOSTS":   []string{"${DB_HOST}", "remote"},
+			"NESTED": map[string]interface{}{
+				"ADDRESS": "${DB_HOST}:${REGION_PORT:-80}",
+				"REGION":  "${FAKE_REGION}",
+			},
+		})).WithVars(map[string]string{
+			"FAKE_PORT":   "5432",
+			"FAKE_NAME":   "fakeName",
+			"FAKE_REGION": "us-east-1",
+			"DB_HOST":     "ignoredHost",
+		})
+
+		err := ss.Decode(&config, decoder)
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, config.Host, "localhost")
+		tt.AssertEqual(t, config.Port, 5432)
+		tt.AssertEqual(t, config.URL, "postgres://fakeEnvUser@localhost:5432/fakeDB")
+		tt.AssertEqual(t, config.Timeout, 30)
+		tt.AssertEqual(t, config.Name, []byte("fakeName"))
+		tt.AssertEqual(t, config.Escaped, "${DB_HOST} is localhost")
+		tt.AssertEqual(t, config.Hosts, []string{"localhost", "remote"})
+		tt.AssertEqual(t, config.Nested.Address, "localhost:80")
+		tt.AssertEqual(t, config.Nested.Region, "us-east-1")
+	})
+
+	t.Run("should work with decoders that return typed values", func(t *testing.T) {
+		var config struct {
+			Port    int    `map:"port"`
+			Address string `map:"address"`
+		}
+		err := ss.Decode(&config, ss.NewInterpolateTagDecoder("map", ss.NewMapTagDecoder("map", map[string]interface{}{
+			"port":    8080,
+			"address": "localhost:${port}",
+		})))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Port, 8080)
+		tt.AssertEqual(t, config.Address, "localhost:8080")
+	})
+
+	t.Run("should ask the wrapped decoder only once for values with no expressions", func(t *testing.T) {
+		calls := map[string]int{}
+		decoder := ss.NewInterpolateTagDecoder("env", ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+			calls[field.Tags["env"]]++
+			return map[string]interface{}{
+				"PORT":    "8080",
+				"ENABLED": "true",
+				"HOST":    "localhost",
+			}[field.Tags["env"]], nil
+		}))
+
+		var config struct {
+			Port    int    `env:"PORT"`
+			Enabled bool   `env:"ENABLED"`
+			Host    string `env:"HOST"`
+			Missing int    `env:"MISSING"`
+		}
+		err := ss.Decode(&config, decoder)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Port, 8080)
+		tt.AssertEqual(t, config.Enabled, true)
+		tt.AssertEqual(t, config.Host, "localhost")
+		tt.AssertEqual(t, calls, map[string]int{
+			"PORT":    1,
+			"ENABLED": 1,
+			"HOST":    1,
+			"MISSING": 1,
+		})
+	})
+
+	t.Run("should report errors", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			sourceMap          map[string]interface{}
+			expectErrToContain []string
+		}{
+			{
+				desc: "undefined variable",
+				sourceMap: map[string]interface{}{
+					"attr1": "${FAKE_UNDEFINED_VAR}",
+				},
+				expectErrToContain: []string{"Attr1", "undefined variable", "FAKE_UNDEFINED_VAR"},
+			},
+			{
+				desc: "self reference",
+				sourceMap: map[string]interface{}{
+					"attr1": "${attr1}",
+				},
+				expectErrToContain: []string{"Attr1", "cycle detected", "attr1 -> attr1"},
+			},
+			{
+				desc: "indirect cycle",
+				sourceMap: map[string]interface{}{
+					"attr1": "${attr2}",
+					"attr2": "${attr3}",
+					"attr3": "${attr2}",
+				},
+				expectErrToContain: []string{"Attr1", "cycle detected", "attr2 -> attr3 -> attr2"},
+			},
+			{
+				desc: "missing closing brace",
+				sourceMap: map[string]interface{}{
+					"attr1": "${attr2",
+				},
+				expectErrToContain: []string{"Attr1", "missing closing brace", "${attr2"},
+			},
+			{
+				desc: "missing variable name",
+				sourceMap: map[string]interface{}{
+					"attr1": "${:-fakeDefault}",
+				},
+				expectErrToContain: []string{"Attr1", "missing variable name"},
+			},
+			{
+				desc: "invalid value after interpolation",
+				sourceMap: map[string]interface{}{
+					"attr1": "fakeValue",
+					"attr2": "${attr1}",
+				},
+				expectErrToContain: []string{"Attr2", "fakeValue"},
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				var config struct {
+					Attr1 string `map:"attr1"`
+					Attr2 int    `map:"attr2"`
+				}
+				err := ss.Decode(&config, ss.NewInterpolateTagDecoder("map", ss.NewStringMapTagDecoder("map", test.sourceMap)))
+				tt.AssertErrContains(t, err, test.expectErrToContain...)
+			})
+		}
+	})
+}
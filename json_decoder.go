@@ -0,0 +1,157 @@
+package structscanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// JSONTagDecoder can be used to fill a struct with the values of a JSON object.
+//
+// Only the keys of the object are parsed when the decoder is created,
+// the value of each key is only decoded when the Decode function asks
+// for it, so there is no need to unmarshal the document into a
+// map[string]interface{} first.
+//
+// Numbers are parsed directly into the type of the target field,
+// so int64 values are not rounded by a conversion to float64.
+type JSONTagDecoder struct {
+	tagName string
+	fields  map[string]json.RawMessage
+}
+
+// NewJSONTagDecoder returns a new decoder for filling a given struct
+// with the values of the JSON object in the data argument.
+//
+// The keys of the object will be mapped to the struct using the key
+// present in the tagName of each field of the struct.
+func NewJSONTagDecoder(tagName string, data json.RawMessage) (JSONTagDecoder, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return JSONTagDecoder{}, fmt.Errorf("error parsing JSON object: %w", err)
+	}
+
+	return JSONTagDecoder{
+		tagName: tagName,
+		fields:  fields,
+	}, nil
+}
+
+// NewJSONTagDecoderFromReader works as NewJSONTagDecoder but reads
+// the JSON object from an io.Reader.
+func NewJSONTagDecoderFromReader(tagName string, reader io.Reader) (JSONTagDecoder, error) {
+	var data json.RawMessage
+	err := json.NewDecoder(reader).Decode(&data)
+	if err != nil {
+		return JSONTagDecoder{}, fmt.Errorf("error reading JSON object: %w", err)
+	}
+
+	return NewJSONTagDecoder(tagName, data)
+}
+
+// DecodeField implements the TagDecoder interface
+func (j JSONTagDecoder) DecodeField(info Field) (interface{}, error) {
+	key := info.Key(j.tagName)
+	raw, found := j.fields[key]
+	if !found || key == "" {
+		return nil, nil
+	}
+
+	// Fields with a registered converter receive the
+	// plain JSON value instead of a nested decoder:
+	if info.hasConverter {
+		return j.decodeValue(interfaceType, raw)
+	}
+
+	return j.decodeValue(info.Type, raw)
+}
+
+func (j JSONTagDecoder) decodeValue(t reflect.Type, raw json.RawMessage) (interface{}, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	elemType := t
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	// Types with their own UnmarshalJSON method, e.g. time.Time,
+	// are always decoded directly by the json package:
+	if !reflect.PointerTo(elemType).Implements(jsonUnmarshalerType) {
+		switch {
+		case elemType.Kind() == reflect.Struct && raw[0] == '{':
+			// By returning a decoder you tell the library to run
+			// it recursively on this nested object:
+			return NewJSONTagDecoder(j.tagName, raw)
+
+		case elemType.Kind() == reflect.Slice && raw[0] == '[':
+			return j.decodeArray(elemType.Elem(), raw)
+
+		case isNumericKind(elemType.Kind()) && isJSONNumber(raw):
+			return parseString(Field{Type: elemType, Kind: elemType.Kind()}, string(raw))
+		}
+	}
+
+	return decodeJSON(t, raw)
+}
+
+func decodeJSON(t reflect.Type, raw json.RawMessage) (interface{}, error) {
+	target := reflect.New(t)
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	err := decoder.Decode(target.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	return target.Elem().Interface(), nil
+}
+
+func (j JSONTagDecoder) decodeArray(elemType reflect.Type, raw json.RawMessage) (interface{}, error) {
+	var items []json.RawMessage
+	err := json.Unmarshal(raw, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items of nested arrays, e.g. of [][]int fields, can't be
+	// converted from []interface{} by Decode, so since they can't
+	// contain nested structs they are decoded by the json package:
+	decodeItem := j.decodeValue
+	if derefType(elemType).Kind() == reflect.Slice && !isStructOrStructPtr(derefType(elemType).Elem()) {
+		decodeItem = decodeJSON
+	}
+
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		values[i], err = decodeItem(elemType, item)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding item %d of JSON array: %w", i, err)
+		}
+	}
+
+	return values, nil
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isJSONNumber(raw json.RawMessage) bool {
+	return raw[0] == '-' || (raw[0] >= '0' && raw[0] <= '9')
+}
@@ -0,0 +1,178 @@
+package structscanner_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestJSONTagDecoder(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+		City   string `json:"city"`
+	}
+
+	type User struct {
+		ID        int64     `json:"id"`
+		Name      string    `json:"name"`
+		Score     *float64  `json:"score"`
+		CreatedAt time.Time `json:"created_at"`
+		Address   Address   `json:"address"`
+		Previous  *Address  `json:"previous"`
+		Others    []Address `json:"others"`
+		Tags      []string  `json:"tags"`
+		Counts    []uint8   `json:"counts"`
+		Extra     any       `json:"extra"`
+	}
+
+	t.Run("should decode JSON objects", func(t *testing.T) {
+		decoder, err := ss.NewJSONTagDecoder("json", json.RawMessage(`{
+			"id": 9007199254740993,
+			"name": "fakeName",
+			"score": 4.5,
+			"created_at": "2024-01-02T03:04:05Z",
+			"address": {"street": "fakeStreet", "city": "fakeCity"},
+			"previous": {"street": "fakePreviousStreet"},
+			"others": [{"city": "fakeCity1"}, {"city": "fakeCity2"}],
+			"tags": ["a", "b"],
+			"counts": [1, 2, 3],
+			"extra": 12345678901234567890,
+			"unused": {"this": ["is", "never", "decoded"]}
+		}`))
+		tt.AssertNoErr(t, err)
+
+		var user User
+		err = ss.Decode(&user, decoder)
+		tt.AssertNoErr(t, err)
+
+		score := 4.5
+		tt.AssertEqual(t, user, User{
+			// This number can't be represented by a float64:
+			ID:        9007199254740993,
+			Name:      "fakeName",
+			Score:     &score,
+			CreatedAt: tt.ParseTime(t, "2024-01-02T03:04:05Z"),
+			Address: Address{
+				Street: "fakeStreet",
+				City:   "fakeCity",
+			},
+			Previous: &Address{
+				Street: "fakePreviousStreet",
+			},
+			Others: []Address{
+				{City: "fakeCity1"},
+				{City: "fakeCity2"},
+			},
+			Tags:   []string{"a", "b"},
+			Counts: []uint8{1, 2, 3},
+			Extra:  json.Number("12345678901234567890"),
+		})
+	})
+
+	t.Run("should ignore missing and null values", func(t *testing.T) {
+		decoder, err := ss.NewJSONTagDecoderFromReader("json", strings.NewReader(`{
+			"name": null,
+			"previous": null
+		}`))
+		tt.AssertNoErr(t, err)
+
+		user := User{
+			ID:   42,
+			Name: "placeholder",
+		}
+		err = ss.Decode(&user, decoder)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, user, User{
+			ID:   42,
+			Name: "placeholder",
+		})
+	})
+
+	t.Run("should decode slices of pointers to structs", func(t *testing.T) {
+		decoder, err := ss.NewJSONTagDecoder("json", json.RawMessage(`{
+			"addresses": [{"street": "fakeStreet"}, null]
+		}`))
+		tt.AssertNoErr(t, err)
+
+		var output struct {
+			Addresses []*Address `json:"addresses"`
+		}
+		err = ss.Decode(&output, decoder)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, output.Addresses, []*Address{
+			{Street: "fakeStreet"},
+			nil,
+		})
+	})
+
+	t.Run("should decode nested arrays", func(t *testing.T) {
+		decoder, err := ss.NewJSONTagDecoder("json", json.RawMessage(`{
+			"matrix": [[1, 2], [3], []],
+			"words": [["a", "b"], null],
+			"optional": [[1]]
+		}`))
+		tt.AssertNoErr(t, err)
+
+		var output struct {
+			Matrix   [][]int     `json:"matrix"`
+			Words    [][]string  `json:"words"`
+			Optional []*[]uint64 `json:"optional"`
+		}
+		err = ss.Decode(&output, decoder)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, output.Matrix, [][]int{{1, 2}, {3}, {}})
+		tt.AssertEqual(t, output.Words, [][]string{{"a", "b"}, nil})
+		tt.AssertEqual(t, output.Optional, []*[]uint64{{1}})
+	})
+
+	t.Run("should report errors", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			json               string
+			expectErrToContain []string
+		}{
+			{
+				desc:               "invalid number for field type",
+				json:               `{"id": 4.5}`,
+				expectErrToContain: []string{"ID", "4.5"},
+			},
+			{
+				desc:               "number overflows field type",
+				json:               `{"counts": [1, 256]}`,
+				expectErrToContain: []string{"Counts", "item 1", "256", "out of range"},
+			},
+			{
+				desc:               "type mismatch",
+				json:               `{"name": {"not": "a string"}}`,
+				expectErrToContain: []string{"Name", "object", "string"},
+			},
+			{
+				desc:               "type mismatch inside nested objects",
+				json:               `{"others": [{"city": 42}]}`,
+				expectErrToContain: []string{"Others[0]", "City", "number", "string"},
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				decoder, err := ss.NewJSONTagDecoder("json", json.RawMessage(test.json))
+				tt.AssertNoErr(t, err)
+
+				var user User
+				err = ss.Decode(&user, decoder)
+				tt.AssertErrContains(t, err, test.expectErrToContain...)
+			})
+		}
+	})
+
+	t.Run("should report error if input is not a JSON object", func(t *testing.T) {
+		_, err := ss.NewJSONTagDecoder("json", json.RawMessage(`[1, 2, 3]`))
+		tt.AssertErrContains(t, err, "error parsing JSON object", "array")
+
+		_, err = ss.NewJSONTagDecoderFromReader("json", strings.NewReader(`{"unterminated": `))
+		tt.AssertErrContains(t, err, "error reading JSON object")
+	})
+}
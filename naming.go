@@ -0,0 +1,128 @@
+package structscanner
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy computes the key of fields that have no tag for
+// the decoder, e.g. the SnakeCase strategy maps "DBPort" to "db_port".
+//
+// It is set with the `WithNaming()` option and used by `Field.Key()`.
+type NamingStrategy func(fieldName string) string
+
+// WithNaming makes the decoders look for the fields that have no tag
+// using a key computed by the given strategy, e.g.:
+//
+//	err := structscanner.Decode(&config, envDecoder, structscanner.WithNaming(structscanner.ScreamingSnakeCase))
+//
+// By default the field name is used as is.
+func WithNaming(strategy NamingStrategy) Option {
+	return func(o *options) {
+		o.naming = strategy
+	}
+}
+
+// Key returns the key the decoders should use for reading the value of
+// this field, which is the value of the given tag, or if the field has
+// no such tag, the field name formatted with the NamingStrategy passed
+// to the `WithNaming()` option.
+//
+// Options after a comma are not part of the key, e.g.
+// the key of `map:"name,omitempty"` is "name".
+//
+// Fields tagged with `-` have no key, so Key returns an empty string
+// and decoders should treat them as not provided, like on encoding/json
+// the tag `-,` can be used for the key "-".
+func (f Field) Key(tagName string) string {
+	key, _, hasOptions := strings.Cut(f.Tags[tagName], ",")
+	if key == "-" && !hasOptions {
+		return ""
+	}
+	if key != "" {
+		return key
+	}
+
+	if f.naming != nil {
+		return f.naming(f.Name)
+	}
+	return f.Name
+}
+
+// AsIs is a NamingStrategy that uses the field name as is, e.g. "DBPort".
+func AsIs(fieldName string) string {
+	return fieldName
+}
+
+// SnakeCase is a NamingStrategy that formats the field name as snake_case, e.g. "db_port".
+func SnakeCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "_"))
+}
+
+// KebabCase is a NamingStrategy that formats the field name as kebab-case, e.g. "db-port".
+func KebabCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "-"))
+}
+
+// CamelCase is a NamingStrategy that formats the field name as camelCase, e.g. "dbPort".
+func CamelCase(fieldName string) string {
+	words := splitWords(fieldName)
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		words[i] = word
+	}
+	return strings.Join(words, "")
+}
+
+// ScreamingSnakeCase is a NamingStrategy that formats the field name
+// as SCREAMING_SNAKE_CASE, e.g. "DB_PORT", which is the usual
+// format of environment variables.
+func ScreamingSnakeCase(fieldName string) string {
+	return strings.ToUpper(strings.Join(splitWords(fieldName), "_"))
+}
+
+// splitWords splits a Go identifier into words, keeping acronyms
+// together and digits attached to the previous word, e.g.:
+// "HTTPServerURL2" becomes ["HTTP", "Server", "URL2"].
+func splitWords(name string) []string {
+	runes := []rune(name)
+
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, curr := runes[i-1], runes[i]
+
+		isBoundary := false
+		switch {
+		case curr == '_':
+			words = appendWord(words, runes[start:i])
+			start = i + 1
+			continue
+		case unicode.IsUpper(curr) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			// e.g. the "P" of "dbPort":
+			isBoundary = true
+		case unicode.IsUpper(prev) && unicode.IsUpper(curr) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// e.g. the "P" of "DBPort", which ends the acronym "DB":
+			isBoundary = true
+		}
+
+		if isBoundary {
+			words = appendWord(words, runes[start:i])
+			start = i
+		}
+	}
+
+	return appendWord(words, runes[start:])
+}
+
+func appendWord(words []string, word []rune) []string {
+	if len(word) == 0 {
+		return words
+	}
+	return append(words, string(word))
+}
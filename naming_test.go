@@ -0,0 +1,168 @@
+package structscanner_test
+
+import (
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	tests := []struct {
+		fieldName         string
+		expectedAsIs      string
+		expectedSnake     string
+		expectedKebab     string
+		expectedCamel     string
+		expectedScreaming string
+	}{
+		{
+			fieldName:         "Port",
+			expectedAsIs:      "Port",
+			expectedSnake:     "port",
+			expectedKebab:     "port",
+			expectedCamel:     "port",
+			expectedScreaming: "PORT",
+		},
+		{
+			fieldName:         "DBPort",
+			expectedAsIs:      "DBPort",
+			expectedSnake:     "db_port",
+			expectedKebab:     "db-port",
+			expectedCamel:     "dbPort",
+			expectedScreaming: "DB_PORT",
+		},
+		{
+			fieldName:         "HTTPServerURL2",
+			expectedAsIs:      "HTTPServerURL2",
+			expectedSnake:     "http_server_url2",
+			expectedKebab:     "http-server-url2",
+			expectedCamel:     "httpServerUrl2",
+			expectedScreaming: "HTTP_SERVER_URL2",
+		},
+		{
+			fieldName:         "Log_Level",
+			expectedAsIs:      "Log_Level",
+			expectedSnake:     "log_level",
+			expectedKebab:     "log-level",
+			expectedCamel:     "logLevel",
+			expectedScreaming: "LOG_LEVEL",
+		},
+		{
+			fieldName:         "Version2Beta",
+			expectedAsIs:      "Version2Beta",
+			expectedSnake:     "version2_beta",
+			expectedKebab:     "version2-beta",
+			expectedCamel:     "version2Beta",
+			expectedScreaming: "VERSION2_BETA",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.fieldName, func(t *testing.T) {
+			tt.AssertEqual(t, ss.AsIs(test.fieldName), test.expectedAsIs)
+			tt.AssertEqual(t, ss.SnakeCase(test.fieldName), test.expectedSnake)
+			tt.AssertEqual(t, ss.KebabCase(test.fieldName), test.expectedKebab)
+			tt.AssertEqual(t, ss.CamelCase(test.fieldName), test.expectedCamel)
+			tt.AssertEqual(t, ss.ScreamingSnakeCase(test.fieldName), test.expectedScreaming)
+		})
+	}
+}
+
+func TestWithNaming(t *testing.T) {
+	type Config struct {
+		DBHost   string
+		DBPort   int
+		LogLevel string `map:"verbosity"`
+		TLS      struct {
+			CertFile string
+		}
+	}
+
+	t.Run("should use the field name as is by default", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"DBHost":    "fakeHost",
+			"verbosity": "debug",
+			"TLS": map[string]interface{}{
+				"CertFile": "fakeCertFile",
+			},
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.DBHost, "fakeHost")
+		tt.AssertEqual(t, config.LogLevel, "debug")
+		tt.AssertEqual(t, config.TLS.CertFile, "fakeCertFile")
+	})
+
+	t.Run("should use the naming strategy for fields without tags", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"db_host":   "fakeHost",
+			"db_port":   5432,
+			"verbosity": "debug",
+			"tls": map[string]interface{}{
+				"cert_file": "fakeCertFile",
+			},
+		}), ss.WithNaming(ss.SnakeCase))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.DBHost, "fakeHost")
+		tt.AssertEqual(t, config.DBPort, 5432)
+		tt.AssertEqual(t, config.LogLevel, "debug")
+		tt.AssertEqual(t, config.TLS.CertFile, "fakeCertFile")
+	})
+
+	t.Run("should work with env style decoders", func(t *testing.T) {
+		var config struct {
+			DBHost string
+			DBPort int
+		}
+		err := ss.Decode(&config, ss.NewStringMapTagDecoder("env", map[string]interface{}{
+			"DB_HOST": "fakeHost",
+			"DB_PORT": "5432",
+		}), ss.WithNaming(ss.ScreamingSnakeCase))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.DBHost, "fakeHost")
+		tt.AssertEqual(t, config.DBPort, 5432)
+	})
+
+	t.Run("should suggest keys computed by the naming strategy", func(t *testing.T) {
+		var config struct {
+			DBHost string
+		}
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"db-host": "fakeHost",
+		}), ss.WithNaming(ss.SnakeCase), ss.Strict())
+		tt.AssertErrContains(t, err, `unknown key "db-host", did you mean "db_host"?`)
+	})
+
+	t.Run("Field.Key should prefer the tag over the field name", func(t *testing.T) {
+		info, err := ss.GetStructInfo(&Config{})
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, info.Fields[0].Key("map"), "DBHost")
+		tt.AssertEqual(t, info.Fields[2].Key("map"), "verbosity")
+	})
+
+	t.Run("Field.Key should ignore tag options and fields tagged with -", func(t *testing.T) {
+		var config struct {
+			Host    string `map:"host,omitempty"`
+			Ignored string `map:"-"`
+			Dash    string `map:"-,"`
+		}
+		info, err := ss.GetStructInfo(&config)
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, info.Fields[0].Key("map"), "host")
+		tt.AssertEqual(t, info.Fields[1].Key("map"), "")
+		tt.AssertEqual(t, info.Fields[2].Key("map"), "-")
+
+		err = ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"host":    "fakeHost",
+			"-":       "fakeDash",
+			"Ignored": "fakeIgnored",
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Host, "fakeHost")
+		tt.AssertEqual(t, config.Ignored, "")
+		tt.AssertEqual(t, config.Dash, "fakeDash")
+	})
+}
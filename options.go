@@ -0,0 +1,55 @@
+package structscanner
+
+// Option is the type used for customizing the behavior
+// of the Decode function, e.g.:
+//
+//	err := structscanner.Decode(&config, decoder, structscanner.WithReport(&report))
+//
+// Options can also be passed to New for setting
+// the defaults of all the calls of a Scanner.
+type Option func(*options)
+
+type options struct {
+	report *DecodeReport
+	strict bool
+	naming NamingStrategy
+
+	disablePanicRecovery bool
+	collectRecordErrors  bool
+	workers              int
+	includeSecrets       bool
+
+	cache   *Cache
+	scanner *Scanner
+}
+
+// parseOptions returns the options of the
+// default Scanner with the opts applied.
+func parseOptions(opts []Option) options {
+	return defaultScanner.parseOptions(opts)
+}
+
+// WithReport makes Decode fill the input report with
+// information about the source of each decoded field.
+//
+// Any data already present in the report is discarded.
+func WithReport(report *DecodeReport) Option {
+	return func(o *options) {
+		o.report = report
+	}
+}
+
+// Strict makes Decode fail if the decoder has keys that don't
+// match any field, e.g. because of a typo on a config file.
+//
+// It only works with decoders that implement the UnusedKeysReporter
+// interface, like the MapTagDecoder and the ChainTagDecoder, and the
+// unknown keys of all nesting levels are reported at once as
+// FieldErrors containing an UnknownKeyError, e.g.:
+//
+//	error decoding field DB: unknown key "adress", did you mean "address"?
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
@@ -0,0 +1,59 @@
+package structscanner
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is reported by Decode inside a FieldError when a decoder,
+// hook, validation or conversion panics while decoding a field, so that
+// a single bad value can't crash the program, e.g.:
+//
+//	error decoding field DB.Port: panic: runtime error: index out of range [1] with length 1
+//
+// Use the `DisablePanicRecovery()` option for letting the panics propagate.
+type PanicError struct {
+	// Value is the original value passed to panic()
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine at the moment of the panic
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error,
+// e.g. for panics caused by runtime errors.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// DisablePanicRecovery makes Decode let the panics of decoders and hooks
+// propagate to the caller instead of returning them as a PanicError,
+// which can be useful for debugging with tools that stop on panics.
+func DisablePanicRecovery() Option {
+	return func(o *options) {
+		o.disablePanicRecovery = true
+	}
+}
+
+// recoverPanic should be deferred for converting panics into a
+// FieldError containing a PanicError, which is stored on errPtr.
+func (d *decodeState) recoverPanic(path string, field Field, errPtr *error) {
+	if d.disablePanicRecovery {
+		return
+	}
+
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	*errPtr = newFieldError(path, field, nil, &PanicError{
+		Value: r,
+		Stack: debug.Stack(),
+	})
+}
@@ -0,0 +1,98 @@
+package structscanner_test
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+type panicHookConfig struct {
+	Host string `map:"host"`
+}
+
+func (c *panicHookConfig) AfterDecode() error {
+	panic("fakePanicOnHook")
+}
+
+func TestPanicRecovery(t *testing.T) {
+	t.Run("should recover panics from decoders", func(t *testing.T) {
+		var config struct {
+			Host string `map:"host"`
+			DB   struct {
+				Port int `map:"port"`
+			} `map:"db"`
+		}
+		err := ss.Decode(&config, ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+			if field.Name == "DB" {
+				return ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+					panic("fakePanic")
+				}), nil
+			}
+			return "fakeHost", nil
+		}))
+		tt.AssertErrContains(t, err, "DB.Port", "panic: fakePanic")
+
+		var fieldErr *ss.FieldError
+		tt.AssertTrue(t, errors.As(err, &fieldErr), "error %#v should contain a FieldError", err)
+		tt.AssertEqual(t, fieldErr.Path, "DB.Port")
+
+		var panicErr *ss.PanicError
+		tt.AssertTrue(t, errors.As(err, &panicErr), "error %#v should contain a PanicError", err)
+		tt.AssertEqual(t, panicErr.Value, "fakePanic")
+		tt.AssertTrue(t, strings.Contains(string(panicErr.Stack), "panic_test.go"), "stack should point to the panic, got: %s", panicErr.Stack)
+	})
+
+	t.Run("should recover runtime errors and keep them unwrappable", func(t *testing.T) {
+		var config struct {
+			Host string `map:"host"`
+		}
+		err := ss.Decode(&config, ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+			var m map[string]string
+			m["host"] = "fakeHost"
+			return m["host"], nil
+		}))
+		tt.AssertErrContains(t, err, "Host", "assignment to entry in nil map")
+
+		var runtimeErr runtime.Error
+		tt.AssertTrue(t, errors.As(err, &runtimeErr), "error %#v should contain a runtime.Error", err)
+	})
+
+	t.Run("should recover panics from hooks", func(t *testing.T) {
+		var config struct {
+			Name   string          `map:"name"`
+			Server panicHookConfig `map:"server"`
+		}
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"name": "fakeName",
+			"server": map[string]interface{}{
+				"host": "fakeHost",
+			},
+		}))
+		tt.AssertErrContains(t, err, "Server", "panic: fakePanicOnHook")
+
+		var panicErr *ss.PanicError
+		tt.AssertTrue(t, errors.As(err, &panicErr), "error %#v should contain a PanicError", err)
+	})
+
+	t.Run("should let panics propagate when recovery is disabled", func(t *testing.T) {
+		var config struct {
+			Host string `map:"host"`
+		}
+
+		var recovered interface{}
+		func() {
+			defer func() {
+				recovered = recover()
+			}()
+
+			_ = ss.Decode(&config, ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+				panic("fakePanic")
+			}), ss.DisablePanicRecovery())
+		}()
+		tt.AssertEqual(t, recovered, "fakePanic")
+	})
+}
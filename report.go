@@ -0,0 +1,179 @@
+package structscanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Named is an optional interface that decoders can implement
+// in order to be identified by name on a DecodeReport.
+type Named interface {
+	Name() string
+}
+
+// DecodeReport describes where the value of each field came from
+// during a call to Decode, it is filled by the `WithReport()` option.
+type DecodeReport struct {
+	Fields []FieldReport
+}
+
+// FieldReport describes how a single field was decoded.
+type FieldReport struct {
+	// Path is the path of the field from the root struct, e.g. "DB.Port"
+	Path string
+
+	// Source is the name of the decoder that provided the value,
+	// or an empty string if no decoder provided a value for it.
+	Source string
+
+	// Value is the raw value returned by the decoder or, for defaults,
+	// the value the field had before the call to Decode, it is
+	// replaced by RedactedValue if the field is a secret.
+	Value interface{}
+
+	// Default is true when no decoder had a value for this field
+	// so it kept the value it had before the call to Decode.
+	Default bool
+}
+
+// Lookup returns the report of the field with the given path.
+func (r DecodeReport) Lookup(path string) (FieldReport, bool) {
+	for _, field := range r.Fields {
+		if field.Path == path {
+			return field, true
+		}
+	}
+	return FieldReport{}, false
+}
+
+// WriteTable writes the report to the writer as a human readable
+// table, which is useful for logging the configuration of a service
+// during its startup.
+func (r DecodeReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tSOURCE\tVALUE")
+	for _, field := range r.Fields {
+		source := field.Source
+		if field.Default {
+			source = "(default)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%v\n", field.Path, source, field.Value)
+	}
+	return tw.Flush()
+}
+
+// String returns the report formatted as a table, see WriteTable.
+func (r DecodeReport) String() string {
+	var b strings.Builder
+	_ = r.WriteTable(&b)
+	return b.String()
+}
+
+// NamedTagDecoder wraps a decoder so it is identified by the
+// given name on a DecodeReport, nested decoders returned by it
+// are also wrapped with the same name.
+func NamedTagDecoder(name string, decoder TagDecoder) TagDecoder {
+	return namedTagDecoder{
+		name:    name,
+		decoder: decoder,
+	}
+}
+
+type namedTagDecoder struct {
+	name    string
+	decoder TagDecoder
+}
+
+// Name implements the Named interface
+func (n namedTagDecoder) Name() string {
+	return n.name
+}
+
+// DecodeField implements the TagDecoder interface
+func (n namedTagDecoder) DecodeField(info Field) (interface{}, error) {
+	return n.DecodeFieldContext(context.Background(), info)
+}
+
+// DecodeFieldContext implements the ContextTagDecoder interface
+func (n namedTagDecoder) DecodeFieldContext(ctx context.Context, info Field) (interface{}, error) {
+	value, err := decodeFieldContext(ctx, n.decoder, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapNested(value, func(nested TagDecoder) TagDecoder {
+		return NamedTagDecoder(n.name, nested)
+	}), nil
+}
+
+// UnusedKeys implements the UnusedKeysReporter interface
+// by forwarding the unused keys of the wrapped decoder.
+func (n namedTagDecoder) UnusedKeys() []string {
+	keys, _ := unusedKeys(n.decoder)
+	return keys
+}
+
+// markKeyUsed implements the keyMarker interface
+func (n namedTagDecoder) markKeyUsed(ctx context.Context, info Field) {
+	markKeyUsed(ctx, n.decoder, info)
+}
+
+// sourceTagDecoder is implemented by decoders that get
+// their values from other decoders, like the ChainTagDecoder,
+// so that the report can tell which one provided each value.
+type sourceTagDecoder interface {
+	decodeFieldWithSource(ctx context.Context, info Field) (value interface{}, source TagDecoder, err error)
+}
+
+func decodeFieldWithSource(ctx context.Context, decoder TagDecoder, info Field) (interface{}, TagDecoder, error) {
+	if d, ok := decoder.(sourceTagDecoder); ok {
+		return d.decodeFieldWithSource(ctx, info)
+	}
+
+	value, err := decodeFieldContext(ctx, decoder, info)
+	return value, decoder, err
+}
+
+func decoderName(decoder TagDecoder) string {
+	if named, ok := decoder.(Named); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", decoder)
+}
+
+func (d *decodeState) reportValue(path string, field Field, source TagDecoder, value interface{}) {
+	if d.report == nil {
+		return
+	}
+
+	if field.IsSecret {
+		value = RedactedValue
+	}
+
+	d.report.Fields = append(d.report.Fields, FieldReport{
+		Path:   path,
+		Source: decoderName(source),
+		Value:  value,
+	})
+}
+
+func (d *decodeState) reportDefault(path string, field Field, fieldValue reflect.Value) {
+	if d.report == nil {
+		return
+	}
+
+	var value interface{} = RedactedValue
+	if !field.IsSecret {
+		value = fieldValue.Interface()
+	}
+
+	d.report.Fields = append(d.report.Fields, FieldReport{
+		Path:    path,
+		Value:   value,
+		Default: true,
+	})
+}
@@ -0,0 +1,122 @@
+package structscanner_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+type fakeEnvDecoder map[string]interface{}
+
+func (e fakeEnvDecoder) Name() string {
+	return "env"
+}
+
+func (e fakeEnvDecoder) DecodeField(field ss.Field) (interface{}, error) {
+	return e[field.Tags["env"]], nil
+}
+
+func TestDecodeReport(t *testing.T) {
+	type Config struct {
+		Host    string        `map:"host" env:"HOST"`
+		Timeout time.Duration `map:"timeout" env:"TIMEOUT"`
+		Ports   []int         `map:"ports"`
+		DB      struct {
+			User string `map:"user"`
+			Name string `map:"name"`
+		} `map:"db"`
+	}
+
+	t.Run("should report the source of each field", func(t *testing.T) {
+		file := ss.NamedTagDecoder("file", ss.NewMapTagDecoder("map", map[string]interface{}{
+			"host":  "fileHost",
+			"ports": []int{80, 443},
+			"db": map[string]interface{}{
+				"user": "fileUser",
+			},
+		}))
+		env := fakeEnvDecoder{
+			"TIMEOUT": 5 * time.Second,
+		}
+
+		config := Config{}
+		config.DB.Name = "defaultName"
+
+		var report ss.DecodeReport
+		err := ss.Decode(&config, ss.ChainTagDecoder(env, file), ss.WithReport(&report))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, report.Fields, []ss.FieldReport{
+			{Path: "Host", Source: "file", Value: "fileHost"},
+			{Path: "Timeout", Source: "env", Value: 5 * time.Second},
+			{Path: "Ports", Source: "file", Value: []int{80, 443}},
+			{Path: "DB.User", Source: "file", Value: "fileUser"},
+			{Path: "DB.Name", Value: "defaultName", Default: true},
+		})
+
+		field, found := report.Lookup("DB.User")
+		tt.AssertEqual(t, found, true)
+		tt.AssertEqual(t, field.Source, "file")
+
+		_, found = report.Lookup("NotAField")
+		tt.AssertEqual(t, found, false)
+	})
+
+	t.Run("should use the type of decoders that have no name", func(t *testing.T) {
+		var output struct {
+			Attr1 string `map:"attr1"`
+		}
+
+		var report ss.DecodeReport
+		err := ss.Decode(&output, ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+			return "fakeValue", nil
+		}), ss.WithReport(&report))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, report.Fields, []ss.FieldReport{
+			{Path: "Attr1", Source: "structscanner.FuncTagDecoder", Value: "fakeValue"},
+		})
+	})
+
+	t.Run("should report each item of slices of structs", func(t *testing.T) {
+		type Item struct {
+			ID int `map:"id"`
+		}
+		var output struct {
+			Items []Item `map:"items"`
+		}
+
+		var report ss.DecodeReport
+		err := ss.Decode(&output, ss.NamedTagDecoder("items", ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+			return []interface{}{
+				ss.NewMapTagDecoder("map", map[string]interface{}{"id": 1}),
+				ss.NewMapTagDecoder("map", map[string]interface{}{"id": 2}),
+			}, nil
+		})), ss.WithReport(&report))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, report.Fields, []ss.FieldReport{
+			{Path: "Items[0].ID", Source: "items", Value: 1},
+			{Path: "Items[1].ID", Source: "items", Value: 2},
+		})
+	})
+
+	t.Run("should render the report as a table", func(t *testing.T) {
+		report := ss.DecodeReport{
+			Fields: []ss.FieldReport{
+				{Path: "Host", Source: "file", Value: "fileHost"},
+				{Path: "DB.Name", Value: "defaultName", Default: true},
+			},
+		}
+
+		tt.AssertEqual(t, report.String(), strings.Join([]string{
+			"FIELD    SOURCE     VALUE",
+			"Host     file       fileHost",
+			"DB.Name  (default)  defaultName",
+			"",
+		}, "\n"))
+	})
+}
@@ -0,0 +1,151 @@
+package structscanner
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// RowsDecoder can be used to fill structs with the rows of a *sql.Rows.
+//
+// The columns returned by the query are mapped to the struct fields
+// using the `db` tag, or the field name for fields without it, e.g.:
+//
+//	rows, err := db.Query("SELECT id, name FROM users")
+//	...
+//	decoder, err := structscanner.NewRowsDecoder(rows)
+//	...
+//	for rows.Next() {
+//		var user User
+//		err := decoder.ScanRow(&user)
+//		...
+//	}
+//
+// Columns that have no matching field are ignored.
+type RowsDecoder struct {
+	tagName string
+	rows    *sql.Rows
+	columns []string
+
+	values map[string]interface{}
+}
+
+// NewRowsDecoder returns a new decoder for scanning the rows of a query
+// into structs.
+func NewRowsDecoder(rows *sql.Rows) (*RowsDecoder, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("error reading columns from sql.Rows: %w", err)
+	}
+
+	return &RowsDecoder{
+		tagName: "db",
+		rows:    rows,
+		columns: columns,
+	}, nil
+}
+
+// ScanRow scans the current row of the *sql.Rows into the targetStruct,
+// so it should only be called after a call to `rows.Next()` returns true.
+//
+// Each column is scanned directly into a holder with the type of its
+// matching field, so NULL values can be received by pointer fields
+// and by types like sql.NullString that implement the sql.Scanner interface.
+//
+// The opts are passed to the Decode function, e.g. `WithNaming(SnakeCase)`
+// for matching fields without the `db` tag to snake_case columns.
+func (r *RowsDecoder) ScanRow(targetStruct interface{}, opts ...Option) error {
+	o := parseOptions(opts)
+	info, err := o.cache.getStructInfoOrType(targetStruct)
+	if err != nil {
+		return err
+	}
+
+	fieldsByColumn := map[string]Field{}
+	for _, field := range info.Fields {
+		field.naming = o.naming
+		if key := field.Key(r.tagName); key != "" {
+			fieldsByColumn[key] = field
+		}
+	}
+
+	hasSecrets := false
+	holders := make([]interface{}, len(r.columns))
+	for i, column := range r.columns {
+		field, found := fieldsByColumn[column]
+		if !found {
+			// Columns with no matching field are still scanned
+			// since rows.Scan() expects one holder per column:
+			holders[i] = new(interface{})
+			continue
+		}
+
+		holders[i] = reflect.New(field.Type).Interface()
+		hasSecrets = hasSecrets || field.IsSecret
+	}
+
+	err = r.rows.Scan(holders...)
+	if err != nil {
+		if hasSecrets {
+			return fmt.Errorf("error scanning row: %w", redactedScanError{err: err})
+		}
+		return fmt.Errorf("error scanning row: %w", err)
+	}
+
+	r.values = make(map[string]interface{}, len(r.columns))
+	for i, column := range r.columns {
+		if _, found := fieldsByColumn[column]; !found {
+			continue
+		}
+
+		r.values[column] = reflect.ValueOf(holders[i]).Elem().Interface()
+	}
+
+	return o.scanner.Decode(targetStruct, r, opts...)
+}
+
+// DecodeField implements the TagDecoder interface
+func (r *RowsDecoder) DecodeField(info Field) (interface{}, error) {
+	return r.values[info.Key(r.tagName)], nil
+}
+
+// ScanAll reads all the remaining rows of the input *sql.Rows
+// into a slice of structs of type T and then closes the rows,
+// the opts are passed to the ScanRow method.
+func ScanAll[T any](rows *sql.Rows, opts ...Option) ([]T, error) {
+	defer rows.Close()
+
+	decoder, err := NewRowsDecoder(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []T{}
+	for rows.Next() {
+		var record T
+		err := decoder.ScanRow(&record, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, record)
+	}
+
+	return results, rows.Err()
+}
+
+// redactedScanError hides the message of the errors returned by rows.Scan
+// for rows with secret columns, since on conversion errors database/sql
+// includes the scanned value on the message, the original error is
+// still available through errors.Unwrap() for programmatic use.
+type redactedScanError struct {
+	err error
+}
+
+func (e redactedScanError) Error() string {
+	return fmt.Sprintf("%T (message redacted since the row has secret columns)", e.err)
+}
+
+func (e redactedScanError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,295 @@
+package structscanner_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestRowsDecoder(t *testing.T) {
+	type User struct {
+		ID        int            `db:"id"`
+		Name      string         `db:"name"`
+		Age       *int           `db:"age"`
+		Nickname  sql.NullString `db:"nickname"`
+		CreatedAt time.Time      `db:"created_at"`
+
+		NotAColumn string
+	}
+
+	createdAt := tt.ParseTime(t, "2024-01-02T03:04:05Z")
+
+	t.Run("should scan rows into structs", func(t *testing.T) {
+		db := newFakeDB(t, fakeResult{
+			columns: []string{"id", "name", "age", "nickname", "created_at"},
+			rows: [][]driver.Value{
+				{int64(1), "fakeName1", int64(42), "fakeNick", createdAt},
+				{int64(2), "fakeName2", nil, nil, createdAt},
+			},
+		})
+
+		rows, err := db.Query("SELECT")
+		tt.AssertNoErr(t, err)
+		defer rows.Close()
+
+		decoder, err := ss.NewRowsDecoder(rows)
+		tt.AssertNoErr(t, err)
+
+		var users []User
+		for rows.Next() {
+			user := User{NotAColumn: "placeholder"}
+			err := decoder.ScanRow(&user)
+			tt.AssertNoErr(t, err)
+			users = append(users, user)
+		}
+		tt.AssertNoErr(t, rows.Err())
+
+		tt.AssertEqual(t, users, []User{
+			{
+				ID:         1,
+				Name:       "fakeName1",
+				Age:        intPtr(42),
+				Nickname:   sql.NullString{String: "fakeNick", Valid: true},
+				CreatedAt:  createdAt,
+				NotAColumn: "placeholder",
+			},
+			{
+				ID:         2,
+				Name:       "fakeName2",
+				Age:        nil,
+				Nickname:   sql.NullString{},
+				CreatedAt:  createdAt,
+				NotAColumn: "placeholder",
+			},
+		})
+	})
+
+	t.Run("should ignore columns with no matching fields", func(t *testing.T) {
+		db := newFakeDB(t, fakeResult{
+			columns: []string{"id", "unknown_column", "name"},
+			rows: [][]driver.Value{
+				{int64(1), "fakeValue", "fakeName"},
+			},
+		})
+
+		rows, err := db.Query("SELECT")
+		tt.AssertNoErr(t, err)
+
+		users, err := ss.ScanAll[User](rows)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, users, []User{
+			{ID: 1, Name: "fakeName"},
+		})
+	})
+
+	t.Run("should match fields without tags using the naming strategy", func(t *testing.T) {
+		db := newFakeDB(t, fakeResult{
+			columns: []string{"user_id", "full_name"},
+			rows: [][]driver.Value{
+				{int64(1), "fakeName"},
+			},
+		})
+
+		rows, err := db.Query("SELECT")
+		tt.AssertNoErr(t, err)
+		defer rows.Close()
+
+		decoder, err := ss.NewRowsDecoder(rows)
+		tt.AssertNoErr(t, err)
+
+		tt.AssertTrue(t, rows.Next())
+
+		var user struct {
+			UserID   int
+			FullName string
+		}
+		err = decoder.ScanRow(&user, ss.WithNaming(ss.SnakeCase))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, user.UserID, 1)
+		tt.AssertEqual(t, user.FullName, "fakeName")
+	})
+
+	t.Run("should use the configuration of the WithScanner option", func(t *testing.T) {
+		db := newFakeDB(t, fakeResult{
+			columns: []string{"user_id", "full_name"},
+			rows: [][]driver.Value{
+				{int64(1), " fakeName "},
+			},
+		})
+
+		rows, err := db.Query("SELECT")
+		tt.AssertNoErr(t, err)
+
+		type User struct {
+			UserID   int
+			FullName string `transform:"shout"`
+		}
+
+		scanner := ss.New(ss.WithNaming(ss.SnakeCase))
+		scanner.RegisterTransform("shout", func(value string) (string, error) {
+			return strings.ToUpper(strings.TrimSpace(value)) + "!", nil
+		})
+
+		users, err := ss.ScanAll[User](rows, ss.WithScanner(scanner))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, users, []User{{UserID: 1, FullName: "FAKENAME!"}})
+	})
+
+	t.Run("ScanAll should return an empty slice if there are no rows", func(t *testing.T) {
+		db := newFakeDB(t, fakeResult{
+			columns: []string{"id", "name"},
+		})
+
+		rows, err := db.Query("SELECT")
+		tt.AssertNoErr(t, err)
+
+		users, err := ss.ScanAll[User](rows)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, users, []User{})
+	})
+
+	t.Run("should report error when scanning NULL into a non pointer field", func(t *testing.T) {
+		db := newFakeDB(t, fakeResult{
+			columns: []string{"id", "name"},
+			rows: [][]driver.Value{
+				{int64(1), nil},
+			},
+		})
+
+		rows, err := db.Query("SELECT")
+		tt.AssertNoErr(t, err)
+
+		_, err = ss.ScanAll[User](rows)
+		tt.AssertErrContains(t, err, "error scanning row", "name")
+	})
+
+	t.Run("should not leak secret values on scan errors", func(t *testing.T) {
+		db := newFakeDB(t, fakeResult{
+			columns: []string{"id", "pin"},
+			rows: [][]driver.Value{
+				{int64(1), "fakePin"},
+			},
+		})
+
+		rows, err := db.Query("SELECT")
+		tt.AssertNoErr(t, err)
+
+		_, err = ss.ScanAll[struct {
+			ID  int               `db:"id"`
+			Pin ss.Secret[uint32] `db:"pin"`
+		}](rows)
+		tt.AssertErrContains(t, err, "error scanning row", "redacted")
+		tt.AssertEqual(t, strings.Contains(err.Error(), "fakePin"), false)
+		tt.AssertTrue(t, strings.Contains(errors.Unwrap(errors.Unwrap(err)).Error(), "fakePin"), "the original error should be available")
+	})
+
+	t.Run("should report error if the target is not a struct", func(t *testing.T) {
+		db := newFakeDB(t, fakeResult{
+			columns: []string{"id"},
+			rows: [][]driver.Value{
+				{int64(1)},
+			},
+		})
+
+		rows, err := db.Query("SELECT")
+		tt.AssertNoErr(t, err)
+
+		_, err = ss.ScanAll[int](rows)
+		tt.AssertErrContains(t, err, "can only get struct info from structs", "int")
+	})
+}
+
+// The code below implements a minimal database/sql/driver
+// so we can test the RowsDecoder without a real database.
+
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var fakeDriverOnce sync.Once
+var fakeResults = sync.Map{}
+
+func newFakeDB(t *testing.T, result fakeResult) *sql.DB {
+	fakeDriverOnce.Do(func() {
+		sql.Register("structscanner-fake", fakeDriver{})
+	})
+
+	dsn := t.Name()
+	fakeResults.Store(dsn, result)
+
+	db, err := sql.Open("structscanner-fake", dsn)
+	tt.AssertNoErr(t, err)
+	t.Cleanup(func() {
+		db.Close()
+		fakeResults.Delete(dsn)
+	})
+
+	return db
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	result, found := fakeResults.Load(dsn)
+	if !found {
+		return nil, fmt.Errorf("no fake result registered for dsn: %q", dsn)
+	}
+	return fakeConn{result: result.(fakeResult)}, nil
+}
+
+type fakeConn struct {
+	result fakeResult
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{result: c.result}, nil
+}
+
+func (c fakeConn) Close() error { return nil }
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported by the fake driver")
+}
+
+type fakeStmt struct {
+	result fakeResult
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("exec is not supported by the fake driver")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{result: s.result}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	idx    int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.result.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.result.rows[r.idx])
+	r.idx++
+	return nil
+}
@@ -1,12 +1,12 @@
 package structscanner
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sync"
 	"unicode"
 
-	"github.com/vingarcia/structscanner/internal/types"
 	"github.com/vingarcia/structscanner/tags"
 )
 
@@ -32,7 +32,12 @@ type TagDecoder interface {
 // about the field that is currently being targeted by the
 // Decode() function.
 type Field struct {
-	idx int
+	idx         int
+	validations []validationRule
+	transforms  []string
+	naming      NamingStrategy
+
+	hasConverter bool
 
 	Tags map[string]string
 	Name string
@@ -40,117 +45,415 @@ type Field struct {
 	Type reflect.Type
 
 	IsEmbeded bool
+
+	// IsSecret is true for fields whose values should never be
+	// displayed, i.e. fields with the `secret:"true"` or `sensitive:"true"`
+	// tags or of type Secret[T], including pointers, slices and maps of it.
+	IsSecret bool
 }
 
 type StructInfo struct {
 	Fields []Field
 }
 
+// Scanner holds the configuration used for decoding, i.e. the default
+// options, the registered validations, transforms and converters and the cache,
+// so that different libraries on the same program can configure
+// the decoding differently without affecting each other, e.g.:
+//
+//	scanner := structscanner.New(structscanner.WithNaming(structscanner.SnakeCase), structscanner.Strict())
+//	scanner.RegisterValidation("even", validateEven)
+//
+//	err := scanner.Decode(&config, decoder)
+//
+// The package-level functions, like Decode and RegisterValidation,
+// use a default Scanner that has no options set, the ones that have
+// no Scanner method, like DecodeSlice, accept the `WithScanner()` option.
+type Scanner struct {
+	defaults options
+
+	registryMutex   sync.RWMutex
+	validationFuncs map[string]ValidationFunc
+	transformFuncs  map[string]TransformFunc
+	converterFuncs  map[reflect.Type]ConverterFunc
+}
+
+// New returns a new Scanner with the built-in validations and transforms
+// and a private unbounded cache, which can be replaced with `WithCache()`.
+//
+// The opts are the defaults for all the calls made with this Scanner,
+// and the options passed to each call are applied after them.
+func New(opts ...Option) *Scanner {
+	s := &Scanner{
+		validationFuncs: make(map[string]ValidationFunc, len(builtinValidationFuncs)),
+		transformFuncs:  make(map[string]TransformFunc, len(builtinTransformFuncs)),
+		converterFuncs:  make(map[reflect.Type]ConverterFunc),
+	}
+	for name, fn := range builtinValidationFuncs {
+		s.validationFuncs[name] = fn
+	}
+	for name, fn := range builtinTransformFuncs {
+		s.transformFuncs[name] = fn
+	}
+
+	s.defaults.scanner = s
+	s.defaults.cache = NewCache(0)
+	for _, opt := range opts {
+		opt(&s.defaults)
+	}
+
+	return s
+}
+
+var defaultScanner = New(WithCache(defaultCache))
+
+// WithScanner makes the functions that have no Scanner method,
+// like DecodeSlice, DecodeEach, ScanAll, RowsDecoder.ScanRow and
+// CSVDecoder.Decode, use the configuration of the given Scanner, e.g.:
+//
+//	users, err := structscanner.DecodeSlice[User](maps, factory, structscanner.WithScanner(scanner))
+//
+// The defaults of the Scanner are applied before the other opts.
+func WithScanner(s *Scanner) Option {
+	return func(o *options) {
+		if s != nil {
+			o.scanner = s
+		}
+	}
+}
+
+// Decode works like the Decode function but using the configuration of this Scanner.
+func (s *Scanner) Decode(targetStruct interface{}, decoder TagDecoder, opts ...Option) error {
+	return s.DecodeContext(context.Background(), targetStruct, decoder, opts...)
+}
+
+// GetStructInfo works like the GetStructInfo function but using the cache of this Scanner.
+func (s *Scanner) GetStructInfo(targetStruct interface{}) (StructInfo, error) {
+	return s.defaults.cache.getStructInfoOrType(targetStruct)
+}
+
+// Encode converts a struct into a map like the ToMap
+// function but using the configuration of this Scanner.
+func (s *Scanner) Encode(tagName string, structPtr interface{}, opts ...Option) (map[string]interface{}, error) {
+	return toMap(tagName, structPtr, s.parseOptions(opts))
+}
+
+// Cache returns the cache used by this Scanner.
+func (s *Scanner) Cache() *Cache {
+	return s.defaults.cache
+}
+
+func (s *Scanner) parseOptions(opts []Option) options {
+	o := s.defaults
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// The `WithScanner()` option replaces the defaults
+	// with the ones of the chosen Scanner:
+	if o.scanner != s {
+		return o.scanner.parseOptions(opts)
+	}
+	return o
+}
+
 // GetStructInfo will return (and cache) information about the given struct.
 //
 // `targetStruct` should either be a pointer to a struct type, or a
 // reflect.Type object of the structure in question
+//
+// The information is stored on the cache returned by DefaultCache().
 func GetStructInfo(targetStruct interface{}) (si StructInfo, err error) {
-	if t, ok := targetStruct.(reflect.Type); ok {
-		if t.Kind() != reflect.Ptr {
-			t = reflect.PointerTo(t)
-		}
-		_, si.Fields, err = getStructInfoForType(t)
-		return si, err
-	}
-
-	_, _, si.Fields, err = getStructInfo(targetStruct)
-	return si, err
+	return defaultScanner.GetStructInfo(targetStruct)
 }
 
 // Decode reads from the input decoder in order to fill the
 // attributes of an target struct.
-func Decode(targetStruct interface{}, decoder TagDecoder) error {
-	t, v, fields, err := getStructInfo(targetStruct)
+//
+// The behavior of Decode can be customized with the opts
+// argument, e.g. `structscanner.WithReport(&report)`.
+//
+// Use DecodeContext for decoders that need a context.
+func Decode(targetStruct interface{}, decoder TagDecoder, opts ...Option) error {
+	return defaultScanner.Decode(targetStruct, decoder, opts...)
+}
+
+// decodeState holds the options of a single call to Decode
+// so they can be used on all of the recursive calls.
+type decodeState struct {
+	options
+
+	ctx context.Context
+
+	// Validation and unknown key errors are collected
+	// so that all of them can be reported at once:
+	validationErrors FieldErrors
+	unknownKeyErrors FieldErrors
+}
+
+func newDecodeState(ctx context.Context, o options) *decodeState {
+	d := &decodeState{
+		options: o,
+		ctx:     context.WithValue(ctx, optionsContextKey{}, o),
+	}
+
+	if d.report != nil {
+		d.report.Fields = nil
+	}
+
+	return d
+}
+
+// decode fills the targetStruct and returns all the
+// errors collected while decoding its fields.
+func (d *decodeState) decode(targetStruct interface{}, decoder TagDecoder) error {
+	err := d.decodeStruct("", targetStruct, decoder)
+	if err != nil {
+		return err
+	}
+
+	errs := append(d.unknownKeyErrors, d.validationErrors...)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (d *decodeState) decodeStruct(path string, targetStruct interface{}, decoder TagDecoder) (err error) {
+	// Panics on the fields are recovered by decodeField, this
+	// one covers the hooks and validations of the struct itself:
+	defer d.recoverPanic(path, Field{}, &err)
+
+	t, v, fields, err := d.cache.getStructInfo(targetStruct)
+	if err != nil {
+		return err
+	}
+
+	err = d.callBeforeDecode(path, targetStruct)
 	if err != nil {
 		return err
 	}
 
 	for _, field := range fields {
-		rawValue, err := decoder.DecodeField(field)
+		field.naming = d.naming
+		field.hasConverter = d.scanner.hasConverter(field.Type)
+
+		err := d.decodeField(path, t, v, field, decoder)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.checkUnusedKeys(path, fields, decoder)
+	d.validateStruct(path, v.Elem(), fields)
+	return d.callAfterDecode(path, targetStruct)
+}
+
+// emptyDecoder provides no values for any field.
+var emptyDecoder = FuncTagDecoder(func(info Field) (interface{}, error) {
+	return nil, nil
+})
+
+// decodeField decodes a single field of the struct
+// pointed by v, whose path is the structPath.
+func (d *decodeState) decodeField(structPath string, t reflect.Type, v reflect.Value, field Field, decoder TagDecoder) (err error) {
+	fieldPath := joinPath(structPath, field.Name)
+	defer d.recoverPanic(fieldPath, field, &err)
+
+	if err := d.ctx.Err(); err != nil {
+		return newFieldError(fieldPath, field, nil, err)
+	}
+
+	rawValue, source, err := decodeFieldWithSource(d.ctx, decoder, field)
+	if err != nil {
+		return newFieldError(fieldPath, field, nil, err)
+	}
+
+	if rawValue == nil {
+		// Nested structs that are not pointers are still decoded with
+		// a decoder that provides no values, so their validations and
+		// hooks run even if the source has no data for them:
+		if isMappableStruct(field.Type, d.cache) && !field.hasConverter {
+			err := d.decodeStruct(fieldPath, v.Elem().Field(field.idx).Addr().Interface(), emptyDecoder)
+			if err != nil {
+				return fmt.Errorf("error decoding nested field %q: %w", t.Field(field.idx).Name, err)
+			}
+			return nil
+		}
+
+		d.reportDefault(fieldPath, field, v.Elem().Field(field.idx))
+		return nil
+	}
+
+	// Maps returned by ToMap without the `IncludeSecrets()`
+	// option would silently overwrite the secrets otherwise:
+	if field.IsSecret && rawValue == RedactedValue {
+		return newFieldError(fieldPath, field, rawValue, RedactedSecretError{})
+	}
+
+	if len(field.transforms) > 0 {
+		transformedValue, err := d.applyTransforms(field, rawValue)
 		if err != nil {
-			return fmt.Errorf("error decoding field %v: %w", t.Field(field.idx), err)
+			return newFieldError(fieldPath, field, rawValue, err)
 		}
+		rawValue = transformedValue
+	}
 
-		if rawValue == nil {
-			continue
+	// Slices are converted item by item unless
+	// there is a converter for the slice type itself:
+	_, hasSliceConverter := d.scanner.getConverterFunc(field.Type)
+	if field.Kind == reflect.Slice && !hasSliceConverter {
+		err := d.decodeSlice(fieldPath, field, v.Elem().Field(field.idx), rawValue, source)
+		if err != nil {
+			return newFieldError(fieldPath, field, rawValue, err)
 		}
+		return nil
+	}
 
-		if field.Kind == reflect.Slice {
-			sliceValue := reflect.ValueOf(rawValue)
-			sliceType := sliceValue.Type()
-			if sliceType.Kind() == reflect.Ptr {
-				sliceType = sliceType.Elem()
-				sliceValue = sliceValue.Elem()
+	nestedDecoder, ok := rawValue.(TagDecoder)
+	if ok {
+		fieldType := v.Elem().Field(field.idx).Type()
+		fieldAddr := v.Elem().Field(field.idx).Addr()
+		if fieldType.Kind() == reflect.Ptr {
+			if fieldAddr.Elem().IsNil() {
+				// If this field is a nil pointer, do struct.Field = new(*T):
+				fieldAddr.Elem().Set(reflect.New(fieldType.Elem()))
 			}
+			// Now since it is a pointer, drop one level for the
+			// decode function to receive a *struct instead of a **struct:
+			fieldAddr = fieldAddr.Elem()
+		}
 
-			if sliceType.Kind() != reflect.Slice {
-				return fmt.Errorf("expected slice for field %#v but got %v of type %v", t.Field(field.idx), sliceValue, sliceType)
-			}
+		err := d.decodeStruct(fieldPath, fieldAddr.Interface(), nestedDecoder)
+		if err != nil {
+			return fmt.Errorf("error decoding nested field %q: %w", t.Field(field.idx).Name, err)
+		}
+		return nil
+	}
 
-			elemType := field.Type.Elem()
+	if isSecretType(field.Type) {
+		err := setSecretValue(v.Elem().Field(field.idx), rawValue)
+		if err != nil {
+			return newFieldError(fieldPath, field, rawValue, err)
+		}
+		d.reportValue(fieldPath, field, source, rawValue)
+		return nil
+	}
 
-			sliceLen := sliceValue.Len()
-			targetSlice := reflect.MakeSlice(field.Type, sliceLen, sliceLen)
-			for i := 0; i < sliceLen; i++ {
-				convertedValue, err := types.NewConverter(sliceValue.Index(i).Interface()).Convert(elemType)
-				if err != nil {
-					return fmt.Errorf("error converting %v[%d]: %w", t.Field(field.idx).Name, i, err)
-				}
+	convertedValue, err := d.convert(rawValue, field.Type)
+	if err != nil {
+		return newFieldError(fieldPath, field, rawValue, err)
+	}
 
-				targetSlice.Index(i).Set(convertedValue)
+	v.Elem().Field(field.idx).Set(convertedValue)
+	d.reportValue(fieldPath, field, source, rawValue)
+	return nil
+}
+
+func (d *decodeState) decodeSlice(
+	path string,
+	field Field,
+	fieldValue reflect.Value,
+	rawValue interface{},
+	source TagDecoder,
+) error {
+	sliceValue := reflect.ValueOf(rawValue)
+	sliceType := sliceValue.Type()
+	if sliceType.Kind() == reflect.Ptr {
+		sliceType = sliceType.Elem()
+		sliceValue = sliceValue.Elem()
+	}
+
+	if sliceType.Kind() != reflect.Slice {
+		return fmt.Errorf("expected slice but got %v of type %v", sliceValue, sliceType)
+	}
+
+	elemType := field.Type.Elem()
+
+	hasNestedDecoders := false
+	sliceLen := sliceValue.Len()
+	targetSlice := reflect.MakeSlice(field.Type, sliceLen, sliceLen)
+	for i := 0; i < sliceLen; i++ {
+		item := sliceValue.Index(i).Interface()
+
+		// Slices of structs are decoded recursively
+		// if the decoder returns one TagDecoder per item:
+		if itemDecoder, ok := item.(TagDecoder); ok {
+			hasNestedDecoders = true
+
+			itemAddr := targetSlice.Index(i).Addr()
+			if elemType.Kind() == reflect.Ptr {
+				targetSlice.Index(i).Set(reflect.New(elemType.Elem()))
+				itemAddr = targetSlice.Index(i)
 			}
 
-			v.Elem().Field(field.idx).Set(targetSlice)
+			err := d.decodeStruct(fmt.Sprintf("%s[%d]", path, i), itemAddr.Interface(), itemDecoder)
+			if err != nil {
+				return fmt.Errorf("error decoding %v[%d]: %w", field.Name, i, err)
+			}
 			continue
 		}
 
-		decoder, ok := rawValue.(TagDecoder)
-		if ok {
-			fieldType := v.Elem().Field(field.idx).Type()
-			fieldAddr := v.Elem().Field(field.idx).Addr()
-			if fieldType.Kind() == reflect.Ptr {
-				if fieldAddr.Elem().IsNil() {
-					// If this field is a nil pointer, do struct.Field = new(*T):
-					fieldAddr.Elem().Set(reflect.New(fieldType.Elem()))
-				}
-				// Now since it is a pointer, drop one level for the
-				// decode function to receive a *struct instead of a **struct:
-				fieldAddr = fieldAddr.Elem()
+		// The items of slices of secrets are never
+		// handled by the plain conversions:
+		if isSecretType(elemType) {
+			if item == nil {
+				continue
 			}
-
-			err := Decode(fieldAddr.Interface(), decoder)
+			err := setSecretValue(targetSlice.Index(i), item)
 			if err != nil {
-				return fmt.Errorf("error decoding nested field %q: %w", t.Field(field.idx).Name, err)
+				return fmt.Errorf("error converting %v[%d]: %w", field.Name, i, err)
 			}
 			continue
 		}
 
-		convertedValue, err := types.NewConverter(rawValue).Convert(field.Type)
+		convertedValue, err := d.convert(item, elemType)
 		if err != nil {
-			return err
+			return fmt.Errorf("error converting %v[%d]: %w", field.Name, i, err)
 		}
 
-		v.Elem().Field(field.idx).Set(convertedValue)
+		targetSlice.Index(i).Set(convertedValue)
+	}
+
+	fieldValue.Set(targetSlice)
+
+	// The items of slices of structs are reported individually:
+	if !hasNestedDecoders {
+		d.reportValue(path, field, source, rawValue)
 	}
 	return nil
 }
 
-// This cache is kept as a pkg variable
-// because the total number of types on a program
-// should be finite. So keeping a single cache here
-// works fine.
-var structInfoCache = &sync.Map{}
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
 
-func getStructInfo(targetStruct interface{}) (reflect.Type, reflect.Value, []Field, error) {
+// getStructInfoOrType implements GetStructInfo for both
+// pointers to structs and reflect.Type objects.
+func (c *Cache) getStructInfoOrType(targetStruct interface{}) (si StructInfo, err error) {
+	if t, ok := targetStruct.(reflect.Type); ok {
+		if t.Kind() != reflect.Ptr {
+			t = reflect.PointerTo(t)
+		}
+		_, si.Fields, err = c.getStructInfoForType(t)
+		return si, err
+	}
+
+	_, _, si.Fields, err = c.getStructInfo(targetStruct)
+	return si, err
+}
+
+func (c *Cache) getStructInfo(targetStruct interface{}) (reflect.Type, reflect.Value, []Field, error) {
 	v := reflect.ValueOf(targetStruct)
+	if !v.IsValid() {
+		return nil, reflect.Value{}, nil, fmt.Errorf("expected non-nil pointer to struct, but got: nil")
+	}
 
-	t, fields, err := getStructInfoForType(v.Type())
+	t, fields, err := c.getStructInfoForType(v.Type())
 	if err != nil {
 		return nil, reflect.Value{}, nil, err
 	}
@@ -163,10 +466,10 @@ func getStructInfo(targetStruct interface{}) (reflect.Type, reflect.Value, []Fie
 	return t, v, fields, err
 }
 
-func getStructInfoForType(ptrType reflect.Type) (reflect.Type, []Field, error) {
-	data, found := structInfoCache.Load(ptrType)
+func (c *Cache) getStructInfoForType(ptrType reflect.Type) (reflect.Type, []Field, error) {
+	fields, found := c.load(ptrType)
 	if found {
-		return ptrType.Elem(), data.([]Field), nil
+		return ptrType.Elem(), fields, nil
 	}
 
 	if ptrType.Kind() != reflect.Ptr {
@@ -191,6 +494,16 @@ func getStructInfoForType(ptrType reflect.Type) (reflect.Type, []Field, error) {
 			return nil, nil, err
 		}
 
+		validations, err := parseValidationRules(parsedTags["validate"])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid validate tag on field %s: %w", field.Name, err)
+		}
+
+		transforms, err := parseTransforms(parsedTags["transform"])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid transform tag on field %s: %w", field.Name, err)
+		}
+
 		info = append(info, Field{
 			idx:  i,
 			Tags: parsedTags,
@@ -200,9 +513,14 @@ func getStructInfoForType(ptrType reflect.Type) (reflect.Type, []Field, error) {
 
 			// ("Anonymous" is the name for embeded fields on the stdlib)
 			IsEmbeded: field.Anonymous,
+
+			IsSecret: isSecretField(field.Type, parsedTags),
+
+			validations: validations,
+			transforms:  transforms,
 		})
 	}
 
-	structInfoCache.Store(ptrType, info)
+	c.store(ptrType, info)
 	return t, info, nil
 }
@@ -1,9 +1,12 @@
 package structscanner_test
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 
 	ss "github.com/vingarcia/structscanner"
@@ -228,6 +231,31 @@ func TestDecode(t *testing.T) {
 			tt.AssertEqual(t, output.Slice, []float64{1.0, 2.0, 3.0})
 		})
 
+		t.Run("should decode slices of structs if a decoder is returned for each item", func(t *testing.T) {
+			decoder := ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+				items := []interface{}{}
+				for i := 1; i <= 2; i++ {
+					i := i
+					items = append(items, ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+						return i, nil
+					}))
+				}
+				return items, nil
+			})
+
+			type Item struct {
+				ID int `map:"id"`
+			}
+			var output struct {
+				Slice    []Item  `map:"slice"`
+				PtrSlice []*Item `map:"ptr_slice"`
+			}
+			err := ss.Decode(&output, decoder)
+			tt.AssertNoErr(t, err)
+			tt.AssertEqual(t, output.Slice, []Item{{ID: 1}, {ID: 2}})
+			tt.AssertEqual(t, output.PtrSlice, []*Item{{ID: 1}, {ID: 2}})
+		})
+
 		t.Run("should work with pointers to slices", func(t *testing.T) {
 			t.Run("source pointer target non-pointer", func(t *testing.T) {
 				decoder := ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
@@ -549,6 +577,209 @@ func TestGetStructInfo(t *testing.T) {
 	})
 }
 
+func TestScanner(t *testing.T) {
+	type Config struct {
+		DBHost string
+		DBPort int    `validate:"even"`
+		Token  string `transform:"reverse"`
+	}
+
+	validateEven := func(value reflect.Value, param string) error {
+		if value.Int()%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	}
+	reverse := func(value string) (string, error) {
+		runes := []rune(value)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	}
+
+	t.Run("should use its own options, registries and cache", func(t *testing.T) {
+		scanner := ss.New(ss.WithNaming(ss.SnakeCase), ss.Strict())
+		scanner.RegisterValidation("even", validateEven)
+		scanner.RegisterTransform("reverse", reverse)
+
+		var config Config
+		err := scanner.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"db_host": "fakeHost",
+			"db_port": 42,
+			"token":   "nekoTekaf",
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config, Config{
+			DBHost: "fakeHost",
+			DBPort: 42,
+			Token:  "fakeToken",
+		})
+
+		err = scanner.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"db_host": "fakeHost",
+			"db_port": 43,
+			"tokne":   "nekoTekaf",
+		}))
+		tt.AssertErrContains(t, err,
+			`unknown key "tokne", did you mean "token"?`,
+			"DBPort: must be even",
+		)
+
+		tt.AssertTrue(t, scanner.Cache() != ss.DefaultCache(), "the scanner should have a private cache")
+		tt.AssertEqual(t, scanner.Cache().Stats(), ss.CacheStats{
+			Hits:    1,
+			Misses:  1,
+			Entries: 1,
+		})
+	})
+
+	t.Run("should not affect the package-level functions", func(t *testing.T) {
+		scanner := ss.New()
+		scanner.RegisterValidation("even", validateEven)
+
+		var config Config
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"DBHost": "fakeHost",
+			"DBPort": 42,
+		}))
+		tt.AssertErrContains(t, err, `unknown validation rule: "even"`)
+	})
+
+	t.Run("should use its own converters", func(t *testing.T) {
+		type Version struct {
+			Major int
+			Minor int
+		}
+		parseVersion := func(value interface{}) (interface{}, error) {
+			var v Version
+			_, err := fmt.Sscanf(fmt.Sprint(value), "v%d.%d", &v.Major, &v.Minor)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version: %v", value)
+			}
+			return v, nil
+		}
+
+		type Labels []string
+		splitLabels := func(value interface{}) (interface{}, error) {
+			return strings.Split(fmt.Sprint(value), ","), nil
+		}
+
+		var output struct {
+			Version     Version
+			MinVersion  *Version
+			Deprecated  []Version
+			Unsupported string
+			Labels      Labels
+		}
+
+		scanner := ss.New()
+		scanner.RegisterConverter(reflect.TypeOf(Version{}), parseVersion)
+		scanner.RegisterConverter(reflect.TypeOf(Labels{}), splitLabels)
+
+		decoder := ss.NewMapTagDecoder("map", map[string]interface{}{
+			"Version":     "v1.2",
+			"MinVersion":  "v1.0",
+			"Deprecated":  []string{"v0.8", "v0.9"},
+			"Unsupported": "v0.1",
+			"Labels":      "stable,lts",
+		})
+		err := scanner.Decode(&output, decoder)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, output.Version, Version{Major: 1, Minor: 2})
+		tt.AssertEqual(t, output.MinVersion, &Version{Major: 1, Minor: 0})
+		tt.AssertEqual(t, output.Deprecated, []Version{{Major: 0, Minor: 8}, {Major: 0, Minor: 9}})
+		tt.AssertEqual(t, output.Unsupported, "v0.1")
+		tt.AssertEqual(t, output.Labels, Labels{"stable", "lts"})
+
+		err = scanner.Decode(&output, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"Version": "latest",
+		}))
+		tt.AssertErrContains(t, err, "Version", "invalid version: latest")
+
+		err = ss.Decode(&output, decoder)
+		tt.AssertErrContains(t, err, "Version")
+	})
+
+	t.Run("should use the converters for struct types on all the decoders", func(t *testing.T) {
+		type Point struct {
+			X int
+			Y int
+		}
+		parsePoint := func(value interface{}) (interface{}, error) {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("expected a map but got %T", value)
+			}
+			var p Point
+			_, err := fmt.Sscanf(fmt.Sprint(m["x"], ",", m["y"]), "%d,%d", &p.X, &p.Y)
+			return p, err
+		}
+
+		scanner := ss.New()
+		scanner.RegisterConverter(reflect.TypeOf(Point{}), parsePoint)
+
+		jsonDecoder, err := ss.NewJSONTagDecoder("json", json.RawMessage(`{"point": {"x": 1, "y": 2}}`))
+		tt.AssertNoErr(t, err)
+
+		type Source struct {
+			Point map[string]interface{} `json:"point"`
+		}
+
+		for desc, decoder := range map[string]ss.TagDecoder{
+			"map":    ss.NewMapTagDecoder("json", map[string]interface{}{"point": map[string]interface{}{"x": 1, "y": 2}}),
+			"json":   jsonDecoder,
+			"struct": ss.NewStructTagDecoder("json", Source{Point: map[string]interface{}{"x": 1, "y": 2}}),
+		} {
+			t.Run(desc, func(t *testing.T) {
+				var output struct {
+					Point Point `json:"point"`
+				}
+				err := scanner.Decode(&output, decoder)
+				tt.AssertNoErr(t, err)
+				tt.AssertEqual(t, output.Point, Point{X: 1, Y: 2})
+			})
+		}
+	})
+
+	t.Run("should accept options on each call", func(t *testing.T) {
+		var config struct {
+			DBHost string
+		}
+
+		scanner := ss.New(ss.WithNaming(ss.SnakeCase))
+		err := scanner.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"DB_HOST": "fakeHost",
+		}), ss.WithNaming(ss.ScreamingSnakeCase))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.DBHost, "fakeHost")
+	})
+
+	t.Run("should get struct info and encode structs", func(t *testing.T) {
+		type User struct {
+			FirstName string
+			Age       int `map:"age"`
+		}
+
+		scanner := ss.New(ss.WithNaming(ss.SnakeCase))
+
+		info, err := scanner.GetStructInfo(&User{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(info.Fields), 2)
+		tt.AssertEqual(t, scanner.Cache().Stats().Entries, 1)
+
+		m, err := scanner.Encode("map", &User{
+			FirstName: "fakeName",
+			Age:       42,
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, m, map[string]interface{}{
+			"first_name": "fakeName",
+			"age":        42,
+		})
+	})
+}
+
 func intPtr(i int) *int {
 	return &i
 }
@@ -0,0 +1,150 @@
+package structscanner
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/vingarcia/structscanner/internal/types"
+)
+
+// RedactedValue is displayed instead of the value
+// of secret fields on errors and reports.
+const RedactedValue = "[REDACTED]"
+
+// Secret is a wrapper for values that should never be displayed,
+// like passwords and API keys.
+//
+// The value can only be read with the Value() method, and it
+// is redacted when the Secret is printed with the fmt package
+// or marshalled into JSON, so it doesn't leak into logs by accident.
+//
+// Secret fields can be decoded by the Decode function as if they
+// were fields of type T.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret wraps a value into a Secret.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Value returns the wrapped value.
+func (s Secret[T]) Value() T {
+	return s.value
+}
+
+// String implements the fmt.Stringer interface
+func (s Secret[T]) String() string {
+	return RedactedValue
+}
+
+// GoString implements the fmt.GoStringer interface
+func (s Secret[T]) GoString() string {
+	return RedactedValue
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedValue)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (s *Secret[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &s.value)
+}
+
+// Scan implements the sql.Scanner interface
+func (s *Secret[T]) Scan(src interface{}) error {
+	return s.setSecret(src)
+}
+
+// secretSetter is implemented by the Secret type so
+// Decode can fill it without knowing the type T.
+type secretSetter interface {
+	setSecret(rawValue interface{}) error
+}
+
+var secretSetterType = reflect.TypeOf((*secretSetter)(nil)).Elem()
+
+// secretGetter allows the wrapped value to be validated.
+type secretGetter interface {
+	secretValue() reflect.Value
+}
+
+func (s Secret[T]) secretValue() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+func (s *Secret[T]) setSecret(rawValue interface{}) error {
+	switch secret := rawValue.(type) {
+	case Secret[T]:
+		*s = secret
+		return nil
+	case *Secret[T]:
+		if secret != nil {
+			*s = *secret
+		}
+		return nil
+	}
+
+	targetType := reflect.TypeOf((*T)(nil)).Elem()
+	if str, ok := rawValue.(string); ok {
+		parsedValue, err := types.StringToType(targetType, str)
+		if err != nil {
+			return err
+		}
+		rawValue = parsedValue.Interface()
+	}
+
+	convertedValue, err := types.NewConverter(rawValue).Convert(targetType)
+	if err != nil {
+		return err
+	}
+
+	s.value = convertedValue.Interface().(T)
+	return nil
+}
+
+// isSecretType returns true for Secret[T] and *Secret[T] types
+func isSecretType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return reflect.PointerTo(t).Implements(secretSetterType)
+}
+
+// holdsSecretType returns true for Secret[T] types and for the
+// pointers, slices, arrays and maps of them, e.g. []Secret[string]
+func holdsSecretType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		if isSecretType(t) {
+			return true
+		}
+		t = t.Elem()
+	}
+	return isSecretType(t)
+}
+
+// setSecretValue fills a Secret[T] or *Secret[T] target with the
+// rawValue, allocating the Secret if the target is a nil pointer.
+func setSecretValue(target reflect.Value, rawValue interface{}) error {
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+	return target.Addr().Interface().(secretSetter).setSecret(rawValue)
+}
+
+func isSecretField(t reflect.Type, parsedTags map[string]string) bool {
+	for _, tagName := range []string{"secret", "sensitive"} {
+		value, found := parsedTags[tagName]
+		if found && value != "false" {
+			return true
+		}
+	}
+
+	return holdsSecretType(t)
+}
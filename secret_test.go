@@ -0,0 +1,232 @@
+package structscanner_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestSecret(t *testing.T) {
+	t.Run("should redact the value when printing or marshalling", func(t *testing.T) {
+		secret := ss.NewSecret("fakePassword")
+
+		tt.AssertEqual(t, secret.Value(), "fakePassword")
+		tt.AssertEqual(t, fmt.Sprint(secret), ss.RedactedValue)
+		tt.AssertEqual(t, fmt.Sprintf("%#v", secret), ss.RedactedValue)
+
+		type Config struct {
+			User     string
+			Password ss.Secret[string]
+		}
+		config := Config{User: "fakeUser", Password: secret}
+		tt.AssertEqual(t, strings.Contains(fmt.Sprintf("%+v", config), "fakePassword"), false)
+
+		b, err := json.Marshal(config)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, string(b), `{"User":"fakeUser","Password":"[REDACTED]"}`)
+	})
+
+	t.Run("should be decoded like a value of the wrapped type", func(t *testing.T) {
+		var config struct {
+			Password ss.Secret[string] `map:"password"`
+			Pin      ss.Secret[int]    `map:"pin"`
+			Token    ss.Secret[string] `map:"token"`
+		}
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"password": "fakePassword",
+			"pin":      "1234",
+			"token":    ss.NewSecret("fakeToken"),
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Password.Value(), "fakePassword")
+		tt.AssertEqual(t, config.Pin.Value(), 1234)
+		tt.AssertEqual(t, config.Token.Value(), "fakeToken")
+	})
+
+	t.Run("should decode pointers to secrets", func(t *testing.T) {
+		var config struct {
+			Password *ss.Secret[string] `map:"password" validate:"min=3"`
+			Pin      *ss.Secret[int]    `map:"pin"`
+			Token    *ss.Secret[string] `map:"token" validate:"min=3"`
+		}
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"password": "fakePassword",
+			"pin":      "1234",
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Password.Value(), "fakePassword")
+		tt.AssertEqual(t, config.Pin.Value(), 1234)
+		tt.AssertEqual(t, config.Token, (*ss.Secret[string])(nil))
+
+		err = ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"token": "ab",
+		}))
+		tt.AssertErrContains(t, err, "Token", "redacted")
+
+		err = ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"pin": "notANumber",
+		}))
+		tt.AssertErrContains(t, err, "Pin", "redacted")
+		tt.AssertEqual(t, strings.Contains(err.Error(), "notANumber"), false)
+	})
+
+	t.Run("should decode slices of secrets", func(t *testing.T) {
+		var config struct {
+			Keys    []ss.Secret[string] `map:"keys"`
+			Pins    []*ss.Secret[int]   `map:"pins"`
+			Servers map[string]ss.Secret[string]
+		}
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"keys": []string{"fakeKey1", "fakeKey2"},
+			"pins": []interface{}{"1234", 42},
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(config.Keys), 2)
+		tt.AssertEqual(t, config.Keys[0].Value(), "fakeKey1")
+		tt.AssertEqual(t, config.Keys[1].Value(), "fakeKey2")
+		tt.AssertEqual(t, len(config.Pins), 2)
+		tt.AssertEqual(t, config.Pins[0].Value(), 1234)
+		tt.AssertEqual(t, config.Pins[1].Value(), 42)
+
+		info, err := ss.GetStructInfo(&config)
+		tt.AssertNoErr(t, err)
+		for _, field := range info.Fields {
+			tt.AssertEqual(t, field.IsSecret, true, field.Name)
+		}
+
+		err = ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"pins": []string{"notANumber"},
+		}))
+		tt.AssertErrContains(t, err, "Pins", "redacted")
+		tt.AssertEqual(t, strings.Contains(err.Error(), "notANumber"), false, err.Error())
+	})
+
+	t.Run("should be decoded by the JSONTagDecoder", func(t *testing.T) {
+		decoder, err := ss.NewJSONTagDecoder("json", json.RawMessage(`{"password": "fakePassword", "keys": ["fakeKey"]}`))
+		tt.AssertNoErr(t, err)
+
+		var config struct {
+			Password ss.Secret[string]   `json:"password"`
+			Keys     []ss.Secret[string] `json:"keys"`
+		}
+		err = ss.Decode(&config, decoder)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Password.Value(), "fakePassword")
+		tt.AssertEqual(t, len(config.Keys), 1)
+		tt.AssertEqual(t, config.Keys[0].Value(), "fakeKey")
+	})
+}
+
+func TestSecretRedaction(t *testing.T) {
+	t.Run("should not leak secret values on conversion errors", func(t *testing.T) {
+		tests := []struct {
+			desc         string
+			targetStruct interface{}
+		}{
+			{
+				desc: "secret tag",
+				targetStruct: &struct {
+					Password int `map:"password" secret:"true"`
+				}{},
+			},
+			{
+				desc: "sensitive tag",
+				targetStruct: &struct {
+					Password int `map:"password" sensitive:"true"`
+				}{},
+			},
+			{
+				desc: "secret type",
+				targetStruct: &struct {
+					Password ss.Secret[int] `map:"password"`
+				}{},
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				err := ss.Decode(test.targetStruct, ss.NewMapTagDecoder("map", map[string]interface{}{
+					"password": "fakePassword",
+				}))
+				tt.AssertErrContains(t, err, "Password", "redacted")
+				tt.AssertEqual(t, strings.Contains(err.Error(), "fakePassword"), false, err.Error())
+
+				var fieldErr *ss.FieldError
+				tt.AssertTrue(t, errors.As(err, &fieldErr), "error %#v should be a FieldError", err)
+				tt.AssertEqual(t, fieldErr.Path, "Password")
+				tt.AssertEqual(t, fieldErr.Value, ss.RedactedValue)
+				tt.AssertEqual(t, fieldErr.IsSecret, true)
+			})
+		}
+	})
+
+	t.Run("should not redact fields tagged with secret false", func(t *testing.T) {
+		var output struct {
+			Attr1 int `map:"attr1" secret:"false"`
+		}
+		err := ss.Decode(&output, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"attr1": "fakeValue",
+		}))
+		tt.AssertErrContains(t, err, "Attr1", "fakeValue")
+	})
+
+	t.Run("should still allow unwrapping the original error", func(t *testing.T) {
+		var output struct {
+			Password int `env:"PASSWORD" secret:"true"`
+		}
+		err := ss.Decode(&output, ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+			return strconv.Atoi("fakePassword")
+		}))
+		tt.AssertErrContains(t, err, "Password", "redacted")
+		tt.AssertEqual(t, strings.Contains(err.Error(), "fakePassword"), false, err.Error())
+
+		var numErr *strconv.NumError
+		tt.AssertTrue(t, errors.As(err, &numErr), "error %#v should wrap a strconv.NumError", err)
+	})
+
+	t.Run("should redact secret values on reports", func(t *testing.T) {
+		config := struct {
+			User     string            `map:"user"`
+			Password string            `map:"password" secret:"true"`
+			Token    ss.Secret[string] `map:"token"`
+			APIKey   string            `map:"api_key" secret:"true"`
+		}{
+			APIKey: "fakeDefaultAPIKey",
+		}
+
+		var report ss.DecodeReport
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"user":     "fakeUser",
+			"password": "fakePassword",
+			"token":    "fakeToken",
+		}), ss.WithReport(&report))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, report.Fields, []ss.FieldReport{
+			{Path: "User", Source: "structscanner.MapTagDecoder", Value: "fakeUser"},
+			{Path: "Password", Source: "structscanner.MapTagDecoder", Value: ss.RedactedValue},
+			{Path: "Token", Source: "structscanner.MapTagDecoder", Value: ss.RedactedValue},
+			{Path: "APIKey", Value: ss.RedactedValue, Default: true},
+		})
+		tt.AssertEqual(t, strings.Contains(report.String(), "fake"+"Password"), false)
+	})
+
+	t.Run("should mark secret fields on the struct info", func(t *testing.T) {
+		info, err := ss.GetStructInfo(&struct {
+			User     string `map:"user"`
+			Password string `map:"password" secret:"true"`
+			Token    ss.Secret[string]
+			APIKey   *ss.Secret[string]
+		}{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, info.Fields[0].IsSecret, false)
+		tt.AssertEqual(t, info.Fields[1].IsSecret, true)
+		tt.AssertEqual(t, info.Fields[2].IsSecret, true)
+		tt.AssertEqual(t, info.Fields[3].IsSecret, true)
+	})
+}
@@ -0,0 +1,189 @@
+package structscanner
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// StructTagDecoder can be used to fill a struct with the fields
+// of another struct, e.g. for copying DTOs into domain structs:
+//
+//	err := structscanner.Decode(&user, structscanner.NewStructTagDecoder("map", userDTO))
+//
+// The fields are matched by their keys, i.e. the value of the tagName
+// or the field name if there is no such tag, and if there is no match
+// the target field is matched by its name. Nested structs and slices
+// of structs are mapped recursively, so their types don't need to match.
+//
+// Unmapped fields can be reported on both sides: the `Strict()`
+// option reports the source fields that no target field asked for,
+// and RequireMapped makes target fields with no source field fail.
+type StructTagDecoder struct {
+	tagName       string
+	source        *structSource
+	keys          *keyTracker
+	requireMapped bool
+}
+
+// structSource holds the fields of the source struct, which are only
+// read on the first call to DecodeField so that the struct info is
+// loaded from the cache of the Decode call, e.g. the one passed
+// with the `WithCache()` option.
+type structSource struct {
+	once  sync.Once
+	value reflect.Value
+
+	values     map[string]interface{}
+	keysByName map[string]string
+	err        error
+}
+
+// NewStructTagDecoder returns a new decoder for filling a given struct with
+// the fields of the source argument, which should be a struct or a pointer
+// to a struct.
+//
+// If the source is not a struct the error is returned by DecodeField.
+func NewStructTagDecoder(tagName string, source interface{}) StructTagDecoder {
+	decoder := StructTagDecoder{
+		tagName: tagName,
+		source:  &structSource{},
+		keys:    newKeyTracker(),
+	}
+
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		decoder.source.err = fmt.Errorf("StructTagDecoder expected a struct or a pointer to a struct, but got: %T", source)
+		return decoder
+	}
+	decoder.source.value = v
+
+	return decoder
+}
+
+func (s *structSource) load(tagName string, cache *Cache) {
+	s.once.Do(func() {
+		if s.err != nil {
+			return
+		}
+
+		_, fields, err := cache.getStructInfoForType(reflect.PointerTo(s.value.Type()))
+		if err != nil {
+			s.err = err
+			return
+		}
+
+		s.values = make(map[string]interface{}, len(fields))
+		s.keysByName = make(map[string]string, len(fields))
+		for _, field := range fields {
+			key := field.Key(tagName)
+			if key == "" {
+				continue
+			}
+			s.values[key] = s.value.Field(field.idx).Interface()
+			s.keysByName[field.Name] = key
+		}
+	})
+}
+
+// RequireMapped returns a copy of the decoder that returns an error
+// for each target field that has no matching field on the source struct,
+// including the fields of nested structs.
+func (s StructTagDecoder) RequireMapped() StructTagDecoder {
+	s.requireMapped = true
+	return s
+}
+
+// DecodeField implements the TagDecoder interface
+func (s StructTagDecoder) DecodeField(info Field) (interface{}, error) {
+	return s.DecodeFieldContext(context.Background(), info)
+}
+
+// DecodeFieldContext implements the ContextTagDecoder interface,
+// the context is only used for finding the cache of the Decode call.
+func (s StructTagDecoder) DecodeFieldContext(ctx context.Context, info Field) (interface{}, error) {
+	cache := activeCache(ctx)
+	s.source.load(s.tagName, cache)
+	if s.source.err != nil {
+		return nil, s.source.err
+	}
+
+	key := info.Key(s.tagName)
+	if key == "" {
+		return nil, nil
+	}
+
+	value, found := s.source.values[key]
+	if !found {
+		key, found = s.source.keysByName[info.Name]
+		value = s.source.values[key]
+	}
+	if !found {
+		if s.requireMapped {
+			return nil, fmt.Errorf("no source field found for key %q", info.Key(s.tagName))
+		}
+		return nil, nil
+	}
+	s.keys.markUsed(key)
+
+	return s.mapValue(info, value, cache), nil
+}
+
+// UnusedKeys implements the UnusedKeysReporter interface
+func (s StructTagDecoder) UnusedKeys() []string {
+	s.source.load(s.tagName, defaultCache)
+	return s.keys.unusedKeys(s.source.values)
+}
+
+// mapValue returns nested decoders for nested structs and slices of
+// structs, even if the source has the same types as the target, so
+// the validations and hooks of the nested structs always run.
+//
+// Fields with a registered converter receive the source value as it is.
+func (s StructTagDecoder) mapValue(info Field, value interface{}, cache *Cache) interface{} {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+
+	if info.hasConverter {
+		return value
+	}
+
+	targetType := info.Type
+
+	if isMappableStruct(derefType(targetType), cache) && isStructOrStructPtr(v.Type()) {
+		return s.nested(value)
+	}
+
+	isSliceOfStructs := targetType.Kind() == reflect.Slice && isMappableStruct(derefType(targetType.Elem()), cache)
+	if isSliceOfStructs && v.Kind() == reflect.Slice && isStructOrStructPtr(v.Type().Elem()) {
+		if v.IsNil() {
+			return nil
+		}
+
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item := v.Index(i)
+			if item.Kind() == reflect.Ptr && item.IsNil() {
+				item = reflect.New(item.Type().Elem())
+			}
+			items[i] = s.nested(item.Interface())
+		}
+		return items
+	}
+
+	return value
+}
+
+// nested returns a decoder for a nested struct
+// with the same configuration of this decoder.
+func (s StructTagDecoder) nested(source interface{}) StructTagDecoder {
+	nested := NewStructTagDecoder(s.tagName, source)
+	nested.requireMapped = s.requireMapped
+	return nested
+}
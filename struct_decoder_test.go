@@ -0,0 +1,188 @@
+package structscanner_test
+
+import (
+	"testing"
+	"time"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+type structDecoderUserDTO struct {
+	ID        int       `map:"id"`
+	Name      string    `map:"name"`
+	Email     string    `map:"email"`
+	Age       int64     `map:"age"`
+	CreatedAt time.Time `map:"created_at"`
+	Password  string    `map:"password"`
+	Address   struct {
+		Street string `map:"street"`
+		City   string `map:"city"`
+	} `map:"address"`
+	Phones []struct {
+		Number string `map:"number"`
+	} `map:"phones"`
+	Manager *struct {
+		Name string `map:"name"`
+	} `map:"manager"`
+
+	Nickname string
+}
+
+type structDecoderPhone struct {
+	Number string `map:"number"`
+}
+
+type structDecoderUser struct {
+	ID        int                `map:"id"`
+	Name      string             `map:"name"`
+	Email     string             `map:"email"`
+	Age       int                `map:"age"`
+	CreatedAt time.Time          `map:"created_at"`
+	Password  ss.Secret[string]  `map:"password"`
+	Address   *structDecoderAddr `map:"address"`
+	Phones    []*structDecoderPhone
+	Manager   *struct {
+		Name string `map:"name"`
+	} `map:"manager"`
+
+	Nickname string `map:"nick"`
+}
+
+type structDecoderAddr struct {
+	Street string `map:"street"`
+	City   string `map:"city"`
+}
+
+func TestStructTagDecoder(t *testing.T) {
+	createdAt := tt.ParseTime(t, "2024-01-02T03:04:05Z")
+
+	newDTO := func() structDecoderUserDTO {
+		dto := structDecoderUserDTO{
+			ID:        42,
+			Name:      "fakeName",
+			Email:     "fake@example.com",
+			Age:       30,
+			CreatedAt: createdAt,
+			Password:  "fakePassword",
+			Nickname:  "fakeNickname",
+		}
+		dto.Address.Street = "fakeStreet"
+		dto.Address.City = "fakeCity"
+		dto.Phones = []struct {
+			Number string `map:"number"`
+		}{
+			{Number: "fakeNumber1"},
+			{Number: "fakeNumber2"},
+		}
+		return dto
+	}
+
+	t.Run("should map the fields of one struct into another", func(t *testing.T) {
+		dto := newDTO()
+
+		var user structDecoderUser
+		err := ss.Decode(&user, ss.NewStructTagDecoder("map", &dto))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, user.ID, 42)
+		tt.AssertEqual(t, user.Name, "fakeName")
+		tt.AssertEqual(t, user.Email, "fake@example.com")
+		tt.AssertEqual(t, user.Age, 30)
+		tt.AssertEqual(t, user.CreatedAt, createdAt)
+		tt.AssertEqual(t, user.Password.Value(), "fakePassword")
+		tt.AssertEqual(t, user.Address, &structDecoderAddr{Street: "fakeStreet", City: "fakeCity"})
+		tt.AssertEqual(t, user.Phones, []*structDecoderPhone{
+			{Number: "fakeNumber1"},
+			{Number: "fakeNumber2"},
+		})
+		tt.AssertEqual(t, user.Manager == nil, true)
+
+		// Fields are also matched by name if the keys don't match:
+		tt.AssertEqual(t, user.Nickname, "fakeNickname")
+	})
+
+	t.Run("should copy structs of the same type", func(t *testing.T) {
+		dto := newDTO()
+		dto.Manager = &struct {
+			Name string `map:"name"`
+		}{Name: "fakeManager"}
+
+		var copied structDecoderUserDTO
+		err := ss.Decode(&copied, ss.NewStructTagDecoder("map", dto))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, copied, dto)
+	})
+
+	t.Run("should validate nested structs of the same type", func(t *testing.T) {
+		type Inner struct {
+			A string `map:"a" validate:"nonempty"`
+		}
+		type Outer struct {
+			In    Inner   `map:"in"`
+			Items []Inner `map:"items"`
+		}
+
+		var target Outer
+		err := ss.Decode(&target, ss.NewStructTagDecoder("map", Outer{
+			In:    Inner{A: "fakeValue"},
+			Items: []Inner{{A: "fakeValue"}, {}},
+		}))
+		tt.AssertErrContains(t, err, "Items[1].A", "must not be empty")
+
+		err = ss.Decode(&target, ss.NewStructTagDecoder("map", Outer{}))
+		tt.AssertErrContains(t, err, "In.A", "must not be empty")
+	})
+
+	t.Run("should report unmapped source fields with the Strict option", func(t *testing.T) {
+		var target struct {
+			Name    string `map:"name"`
+			Address struct {
+				City string `map:"city"`
+			} `map:"address"`
+		}
+		err := ss.Decode(&target, ss.NewStructTagDecoder("map", newDTO()), ss.Strict())
+		tt.AssertErrContains(t, err,
+			`unknown key "email"`,
+			`unknown key "Nickname"`,
+			`Address: unknown key "street"`,
+		)
+	})
+
+	t.Run("should report unmapped target fields with RequireMapped", func(t *testing.T) {
+		var target struct {
+			Name    string `map:"name"`
+			Address struct {
+				City    string `map:"city"`
+				Country string `map:"country"`
+			} `map:"address"`
+		}
+		err := ss.Decode(&target, ss.NewStructTagDecoder("map", newDTO()).RequireMapped())
+		tt.AssertErrContains(t, err, "Address.Country", "no source field", "country")
+	})
+
+	t.Run("should report error if the source is not a struct", func(t *testing.T) {
+		var target struct {
+			Name string `map:"name"`
+		}
+		err := ss.Decode(&target, ss.NewStructTagDecoder("map", map[string]interface{}{}))
+		tt.AssertErrContains(t, err, "StructTagDecoder", "map[string]interface {}")
+	})
+
+	t.Run("should use the cache of the Decode call", func(t *testing.T) {
+		type Source struct {
+			Name string `map:"name"`
+		}
+		var target struct {
+			Name string `map:"name"`
+		}
+
+		ss.DefaultCache().Reset()
+		cache := ss.NewCache(0)
+		err := ss.Decode(&target, ss.NewStructTagDecoder("map", Source{Name: "fakeName"}), ss.WithCache(cache))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, target.Name, "fakeName")
+		tt.AssertEqual(t, cache.Stats().Entries, 2)
+		tt.AssertEqual(t, ss.DefaultCache().Stats().Entries, 0)
+	})
+}
@@ -0,0 +1,139 @@
+package structscanner
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ToMap converts a struct into a map using the keys of the given tagName,
+// which is the inverse of the MapTagDecoder, so the output map can be used
+// for filling a struct of the same type without losing any information:
+//
+//	m, err := structscanner.ToMap("map", &config, structscanner.IncludeSecrets())
+//	...
+//	err = structscanner.Decode(&copied, structscanner.NewMapTagDecoder("map", m))
+//
+// Nested structs become nested maps and slices of structs become slices
+// of maps, other values, including struct values with no exported fields
+// like time.Time and Secret[T], are stored as they are.
+//
+// The values of fields tagged with `secret:"true"` or `sensitive:"true"`
+// are replaced by RedactedValue unless the `IncludeSecrets()` option
+// is used, Secret[T] values are kept since they are already redacted
+// when printed or marshalled.
+//
+// So the round trip is only lossless with the `IncludeSecrets()` option,
+// without it Decode returns a RedactedSecretError for the secret fields
+// instead of silently writing RedactedValue into them.
+//
+// Fields tagged with `-` are skipped, and so are fields with the
+// `omitempty` option, e.g. `map:"name,omitempty"`, when they have their
+// zero value. Fields without the tag use the NamingStrategy passed
+// to the `WithNaming()` option, the other options are ignored.
+func ToMap(tagName string, structPtr interface{}, opts ...Option) (map[string]interface{}, error) {
+	return defaultScanner.Encode(tagName, structPtr, opts...)
+}
+
+// IncludeSecrets makes ToMap output the values of the fields tagged
+// with `secret:"true"` or `sensitive:"true"` instead of RedactedValue,
+// which is needed when the map is used for decoding a struct again.
+func IncludeSecrets() Option {
+	return func(o *options) {
+		o.includeSecrets = true
+	}
+}
+
+func toMap(tagName string, structPtr interface{}, o options) (map[string]interface{}, error) {
+	_, v, fields, err := o.cache.getStructInfo(structPtr)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		field.naming = o.naming
+
+		key := field.Key(tagName)
+		if key == "" {
+			continue
+		}
+		_, tagOptions, _ := strings.Cut(field.Tags[tagName], ",")
+
+		fieldValue := v.Elem().Field(field.idx)
+		if hasTagOption(tagOptions, "omitempty") && fieldValue.IsZero() {
+			continue
+		}
+
+		if field.IsSecret && !holdsSecretType(field.Type) && !o.includeSecrets {
+			m[key] = RedactedValue
+			continue
+		}
+
+		value, err := toMapValue(tagName, fieldValue, o)
+		if err != nil {
+			return nil, err
+		}
+
+		m[key] = value
+	}
+
+	return m, nil
+}
+
+func toMapValue(tagName string, v reflect.Value, o options) (interface{}, error) {
+	switch {
+	case isMappableStruct(v.Type(), o.cache):
+		return toMap(tagName, v.Addr().Interface(), o)
+
+	case v.Kind() == reflect.Ptr && isMappableStruct(v.Type().Elem(), o.cache):
+		if v.IsNil() {
+			return nil, nil
+		}
+		return toMap(tagName, v.Interface(), o)
+
+	case v.Kind() == reflect.Slice && isMappableStruct(derefType(v.Type().Elem()), o.cache):
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		items := make([]map[string]interface{}, v.Len())
+		for i := range items {
+			item, err := toMapValue(tagName, v.Index(i), o)
+			if err != nil {
+				return nil, err
+			}
+
+			items[i], _ = item.(map[string]interface{})
+		}
+		return items, nil
+	}
+
+	return v.Interface(), nil
+}
+
+// isMappableStruct returns true for the structs that
+// should be converted into nested maps by ToMap.
+func isMappableStruct(t reflect.Type, cache *Cache) bool {
+	if t.Kind() != reflect.Struct || isSecretType(t) {
+		return false
+	}
+
+	_, fields, err := cache.getStructInfoForType(reflect.PointerTo(t))
+	return err == nil && len(fields) > 0
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+func hasTagOption(tagOptions string, option string) bool {
+	for _, o := range strings.Split(tagOptions, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package structscanner_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+type toMapAddress struct {
+	Street string `map:"street"`
+	City   string `map:"city,omitempty"`
+}
+
+type toMapUser struct {
+	ID        int               `map:"id"`
+	Name      string            `map:"name,omitempty"`
+	Nickname  *string           `map:"nickname"`
+	CreatedAt time.Time         `map:"created_at"`
+	DeletedAt *time.Time        `map:"deleted_at"`
+	Password  ss.Secret[string] `map:"password"`
+	APIKey    string            `map:"api_key" secret:"true"`
+	Address   toMapAddress      `map:"address"`
+	Billing   *toMapAddress     `map:"billing"`
+	Shipping  *toMapAddress     `map:"shipping"`
+	Previous  []toMapAddress    `map:"previous"`
+	Others    []*toMapAddress   `map:"others"`
+	Tags      []string          `map:"tags"`
+	Scores    map[string]int    `map:"scores"`
+	Internal  string            `map:"-"`
+	Dash      string            `map:"-,"`
+	LogLevel  string
+}
+
+func TestToMap(t *testing.T) {
+	createdAt := tt.ParseTime(t, "2024-01-02T03:04:05Z")
+	nickname := "fakeNickname"
+
+	newUser := func() toMapUser {
+		return toMapUser{
+			ID:        42,
+			Nickname:  &nickname,
+			CreatedAt: createdAt,
+			Password:  ss.NewSecret("fakePassword"),
+			APIKey:    "fakeAPIKey",
+			Address:   toMapAddress{Street: "fakeStreet", City: "fakeCity"},
+			Billing:   &toMapAddress{Street: "fakeBillingStreet"},
+			Previous:  []toMapAddress{{Street: "fakeStreet1"}, {Street: "fakeStreet2"}},
+			Others:    []*toMapAddress{{Street: "fakeStreet3"}},
+			Tags:      []string{"tag1", "tag2"},
+			Scores:    map[string]int{"fakeScore": 10},
+			Internal:  "fakeInternal",
+			Dash:      "fakeDash",
+			LogLevel:  "debug",
+		}
+	}
+
+	t.Run("should convert structs into nested maps", func(t *testing.T) {
+		user := newUser()
+		m, err := ss.ToMap("map", &user)
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, m, map[string]interface{}{
+			"id":         42,
+			"nickname":   &nickname,
+			"created_at": createdAt,
+			"deleted_at": (*time.Time)(nil),
+			"password":   ss.NewSecret("fakePassword"),
+			"api_key":    ss.RedactedValue,
+			"address": map[string]interface{}{
+				"street": "fakeStreet",
+				"city":   "fakeCity",
+			},
+			"billing": map[string]interface{}{
+				"street": "fakeBillingStreet",
+			},
+			"shipping": nil,
+			"previous": []map[string]interface{}{
+				{"street": "fakeStreet1"},
+				{"street": "fakeStreet2"},
+			},
+			"others": []map[string]interface{}{
+				{"street": "fakeStreet3"},
+			},
+			"tags":     []string{"tag1", "tag2"},
+			"scores":   map[string]int{"fakeScore": 10},
+			"-":        "fakeDash",
+			"LogLevel": "debug",
+		})
+	})
+
+	t.Run("should use the naming strategy for fields without tags", func(t *testing.T) {
+		var config struct {
+			LogLevel string
+			Port     int `map:"port"`
+		}
+		m, err := ss.ToMap("map", &config, ss.WithNaming(ss.SnakeCase))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, m, map[string]interface{}{
+			"log_level": "",
+			"port":      0,
+		})
+	})
+
+	t.Run("should round trip through the MapTagDecoder", func(t *testing.T) {
+		user := newUser()
+		m, err := ss.ToMap("map", &user, ss.IncludeSecrets())
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, m["api_key"], "fakeAPIKey")
+
+		var decoded toMapUser
+		err = ss.Decode(&decoded, ss.NewMapTagDecoder("map", m), ss.Strict())
+		tt.AssertNoErr(t, err)
+
+		expected := newUser()
+		expected.Internal = ""
+		tt.AssertEqual(t, decoded, expected)
+	})
+
+	t.Run("should not decode redacted secrets back", func(t *testing.T) {
+		user := newUser()
+		m, err := ss.ToMap("map", &user)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, m["api_key"], ss.RedactedValue)
+
+		var decoded toMapUser
+		err = ss.Decode(&decoded, ss.NewMapTagDecoder("map", m))
+		tt.AssertErrContains(t, err, "APIKey", "RedactedSecretError")
+		tt.AssertTrue(t, errors.As(err, &ss.RedactedSecretError{}), "error %#v should be a RedactedSecretError", err)
+	})
+
+	t.Run("should report error for invalid inputs", func(t *testing.T) {
+		_, err := ss.ToMap("map", toMapUser{})
+		tt.AssertErrContains(t, err, "expected struct pointer")
+
+		_, err = ss.ToMap("map", (*toMapUser)(nil))
+		tt.AssertErrContains(t, err, "non-nil pointer")
+
+		_, err = ss.ToMap("map", nil)
+		tt.AssertErrContains(t, err, "non-nil pointer", "nil")
+	})
+}
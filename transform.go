@@ -0,0 +1,150 @@
+package structscanner
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// TransformFunc transforms a raw string value before
+// it is converted into the type of the field.
+type TransformFunc func(value string) (string, error)
+
+// RegisterTransform registers a new transform that can be used on the
+// `transform` tag of any struct, replacing any transform with the same name.
+//
+// Transforms are applied by Decode, in the order they appear on the tag,
+// to the raw values returned by the decoder, e.g.:
+//
+//	type Config struct {
+//		LogLevel string `env:"LOG_LEVEL" transform:"trim,lower"`
+//		Key      []byte `env:"KEY" transform:"base64"`
+//	}
+//
+// Transforms can only be applied to values of type string, *string or []string.
+//
+// The transform is registered on the default Scanner, use
+// `Scanner.RegisterTransform()` for registering it on another instance.
+func RegisterTransform(name string, fn TransformFunc) {
+	defaultScanner.RegisterTransform(name, fn)
+}
+
+// RegisterTransform works like the RegisterTransform
+// function but only for this Scanner instance.
+func (s *Scanner) RegisterTransform(name string, fn TransformFunc) {
+	s.registryMutex.Lock()
+	defer s.registryMutex.Unlock()
+	s.transformFuncs[name] = fn
+}
+
+// builtinTransformFuncs are the transforms available on every new Scanner:
+var builtinTransformFuncs = map[string]TransformFunc{
+	"trim": func(value string) (string, error) {
+		return strings.TrimSpace(value), nil
+	},
+	"lower": func(value string) (string, error) {
+		return strings.ToLower(value), nil
+	},
+	"upper": func(value string) (string, error) {
+		return strings.ToUpper(value), nil
+	},
+	"expand": func(value string) (string, error) {
+		return os.ExpandEnv(value), nil
+	},
+	"base64": func(value string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(value)
+		return string(b), err
+	},
+	"hex": func(value string) (string, error) {
+		b, err := hex.DecodeString(value)
+		return string(b), err
+	},
+	"file": func(path string) (string, error) {
+		b, err := os.ReadFile(path)
+		return trimTrailingNewline(string(b)), err
+	},
+}
+
+func (s *Scanner) getTransformFunc(name string) (TransformFunc, bool) {
+	s.registryMutex.RLock()
+	defer s.registryMutex.RUnlock()
+	fn, found := s.transformFuncs[name]
+	return fn, found
+}
+
+func parseTransforms(tag string) ([]string, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	names := strings.Split(tag, ",")
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+		if names[i] == "" {
+			return nil, fmt.Errorf("missing transform name on: %q", tag)
+		}
+	}
+
+	return names, nil
+}
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+func (d *decodeState) applyTransforms(field Field, rawValue interface{}) (interface{}, error) {
+	switch v := rawValue.(type) {
+	case string:
+		result, err := d.applyTransformsToString(field.transforms, v)
+		if err != nil {
+			return nil, err
+		}
+
+		// Transforms like base64 and hex are often used for binary data:
+		if field.Type == bytesType {
+			return []byte(result), nil
+		}
+
+		// Since the transforms work with strings we parse the
+		// result into the type of the field when necessary,
+		// e.g. for `transform:"trim"` on an int field:
+		return parseString(field, result)
+
+	case *string:
+		if v == nil {
+			return v, nil
+		}
+		return d.applyTransforms(field, *v)
+
+	case []string:
+		results := make([]string, len(v))
+		for i, item := range v {
+			result, err := d.applyTransformsToString(field.transforms, item)
+			if err != nil {
+				return nil, fmt.Errorf("error transforming item %d: %w", i, err)
+			}
+			results[i] = result
+		}
+		return results, nil
+	}
+
+	return nil, fmt.Errorf("transforms can only be applied to strings, but got value of type %T", rawValue)
+}
+
+func (d *decodeState) applyTransformsToString(transforms []string, value string) (string, error) {
+	for _, name := range transforms {
+		fn, found := d.scanner.getTransformFunc(name)
+		if !found {
+			return "", fmt.Errorf("unknown transform: %q", name)
+		}
+
+		var err error
+		value, err = fn(value)
+		if err != nil {
+			return "", fmt.Errorf("error on transform %q: %w", name, err)
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,143 @@
+package structscanner_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestTransforms(t *testing.T) {
+	t.Run("should apply the built-in transforms", func(t *testing.T) {
+		t.Setenv("FAKE_HOST", "fakeHost")
+
+		filePath := filepath.Join(t.TempDir(), "fakeFile")
+		err := os.WriteFile(filePath, []byte("fakeFileContent"), 0o600)
+		tt.AssertNoErr(t, err)
+
+		workersFilePath := filepath.Join(t.TempDir(), "fakeWorkersFile")
+		err = os.WriteFile(workersFilePath, []byte("42\n"), 0o600)
+		tt.AssertNoErr(t, err)
+
+		var config struct {
+			LogLevel string   `map:"log_level" transform:"trim,lower"`
+			Region   string   `map:"region" transform:"upper"`
+			URL      string   `map:"url" transform:"expand"`
+			Key      []byte   `map:"key" transform:"base64"`
+			Token    string   `map:"token" transform:"hex"`
+			Cert     string   `map:"cert" transform:"file"`
+			Workers  int      `map:"workers" transform:"file"`
+			Port     int      `map:"port" transform:"trim"`
+			Hosts    []string `map:"hosts" transform:"trim"`
+			Optional *string  `map:"optional" transform:"trim"`
+		}
+		optional := "  fakeOptional  "
+		err = ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"log_level": "  DEBUG\n",
+			"region":    "us-east-1",
+			"url":       "postgres://${FAKE_HOST}:5432",
+			"key":       "ZmFrZUtleQ==",
+			"token":     "66616b65546f6b656e",
+			"cert":      filePath,
+			"workers":   workersFilePath,
+			"port":      " 8080 ",
+			"hosts":     []string{" host1 ", "host2 "},
+			"optional":  &optional,
+		}))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, config.LogLevel, "debug")
+		tt.AssertEqual(t, config.Region, "US-EAST-1")
+		tt.AssertEqual(t, config.URL, "postgres://fakeHost:5432")
+		tt.AssertEqual(t, config.Key, []byte("fakeKey"))
+		tt.AssertEqual(t, config.Token, "fakeToken")
+		tt.AssertEqual(t, config.Cert, "fakeFileContent")
+		tt.AssertEqual(t, config.Workers, 42)
+		tt.AssertEqual(t, config.Port, 8080)
+		tt.AssertEqual(t, config.Hosts, []string{"host1", "host2"})
+		tt.AssertEqual(t, *config.Optional, "fakeOptional")
+	})
+
+	t.Run("should support user registered transforms", func(t *testing.T) {
+		ss.RegisterTransform("reverse", func(value string) (string, error) {
+			runes := []rune(value)
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return string(runes), nil
+		})
+
+		var output struct {
+			Attr1 string `map:"attr1" transform:"trim,reverse,upper"`
+		}
+		err := ss.Decode(&output, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"attr1": " abc ",
+		}))
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, output.Attr1, "CBA")
+	})
+
+	t.Run("should report errors", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			value              interface{}
+			targetStruct       interface{}
+			expectErrToContain []string
+		}{
+			{
+				desc:  "unknown transform",
+				value: "fakeValue",
+				targetStruct: &struct {
+					Attr1 string `map:"attr1" transform:"notATransform"`
+				}{},
+				expectErrToContain: []string{"Attr1", "unknown transform", "notATransform"},
+			},
+			{
+				desc:  "transform failure",
+				value: "not base64!",
+				targetStruct: &struct {
+					Attr1 []byte `map:"attr1" transform:"base64"`
+				}{},
+				expectErrToContain: []string{"Attr1", "base64", "illegal base64 data"},
+			},
+			{
+				desc:  "non string value",
+				value: 42,
+				targetStruct: &struct {
+					Attr1 int `map:"attr1" transform:"trim"`
+				}{},
+				expectErrToContain: []string{"Attr1", "only be applied to strings", "int"},
+			},
+			{
+				desc:  "malformed tag",
+				value: "fakeValue",
+				targetStruct: &struct {
+					Attr1 string `map:"attr1" transform:"trim,"`
+				}{},
+				expectErrToContain: []string{"Attr1", "invalid transform tag", "missing transform name"},
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				err := ss.Decode(test.targetStruct, ss.NewMapTagDecoder("map", map[string]interface{}{
+					"attr1": test.value,
+				}))
+				tt.AssertErrContains(t, err, test.expectErrToContain...)
+			})
+		}
+	})
+
+	t.Run("should not leak secret values when a transform fails", func(t *testing.T) {
+		var output struct {
+			Password []byte `map:"password" transform:"base64" secret:"true"`
+		}
+		err := ss.Decode(&output, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"password": "fake password",
+		}))
+		tt.AssertErrContains(t, err, "Password", "redacted")
+		tt.AssertEqual(t, strings.Contains(err.Error(), "fake password"), false, err.Error())
+	})
+}
@@ -0,0 +1,257 @@
+package structscanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UnusedKeysReporter is an optional interface that decoders can implement
+// for reporting the keys of their data source that were not read by any
+// field, which is used by the `Strict()` option for detecting typos.
+//
+// Nested decoders are checked separately after their
+// structs are decoded, so each decoder only needs to
+// report the unused keys of its own nesting level.
+type UnusedKeysReporter interface {
+	UnusedKeys() []string
+}
+
+// UnknownKeyError is reported by Decode with the `Strict()` option
+// for each key of the data source that doesn't match any field.
+type UnknownKeyError struct {
+	Key string
+
+	// Suggestion is the key of the field with the closest
+	// name to the unknown key, if any is close enough.
+	Suggestion string
+}
+
+func (e *UnknownKeyError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown key %q, did you mean %q?", e.Key, e.Suggestion)
+	}
+	return fmt.Sprintf("unknown key %q", e.Key)
+}
+
+// keyTracker records the keys of a source map that were read
+// so that the decoders using it can implement UnusedKeysReporter.
+//
+// It is shared by all the copies of the decoder so
+// the decoders themselves can be passed by value.
+type keyTracker struct {
+	mutex sync.Mutex
+	used  map[string]bool
+
+	// nested holds the decoders of the nested maps
+	// that were only read through paths, by their key:
+	nested map[string]UnusedKeysReporter
+}
+
+func newKeyTracker() *keyTracker {
+	return &keyTracker{
+		used: map[string]bool{},
+	}
+}
+
+func (k *keyTracker) markUsed(key string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.used[key] = true
+}
+
+// trackNested returns the decoder stored for the given key,
+// creating it with newDecoder on the first call.
+func (k *keyTracker) trackNested(key string, newDecoder func() UnusedKeysReporter) UnusedKeysReporter {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if k.nested == nil {
+		k.nested = map[string]UnusedKeysReporter{}
+	}
+
+	decoder, found := k.nested[key]
+	if !found {
+		decoder = newDecoder()
+		k.nested[key] = decoder
+	}
+	return decoder
+}
+
+func (k *keyTracker) nestedReporter(key string) (UnusedKeysReporter, bool) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	decoder, found := k.nested[key]
+	return decoder, found
+}
+
+func (k *keyTracker) unusedKeys(sourceMap map[string]interface{}) []string {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	var unused []string
+	for key := range sourceMap {
+		if !k.used[key] {
+			unused = append(unused, key)
+		}
+	}
+
+	sort.Strings(unused)
+	return unused
+}
+
+// unusedKeys returns the unused keys of decoders that implement
+// the UnusedKeysReporter interface, it is also used by the decoders
+// that wrap other decoders for forwarding their unused keys.
+func unusedKeys(decoder TagDecoder) ([]string, bool) {
+	reporter, ok := decoder.(UnusedKeysReporter)
+	if !ok {
+		return nil, false
+	}
+	return reporter.UnusedKeys(), true
+}
+
+// keyMarker is implemented by the decoders that wrap other decoders
+// so that the ChainTagDecoder can mark the keys of a field as used
+// on them without reading their values, e.g. without reading files.
+type keyMarker interface {
+	markKeyUsed(ctx context.Context, info Field)
+}
+
+// markKeyUsed marks the key of the field as used on decoders that
+// track their unused keys, decoders that don't are not asked at all.
+func markKeyUsed(ctx context.Context, decoder TagDecoder, info Field) {
+	switch d := decoder.(type) {
+	case keyMarker:
+		d.markKeyUsed(ctx, info)
+	case UnusedKeysReporter:
+		// The value and errors are ignored since the
+		// lookup itself is what marks the key as used:
+		_, _ = decodeFieldContext(ctx, decoder, info)
+	}
+}
+
+func (d *decodeState) checkUnusedKeys(path string, fields []Field, decoder TagDecoder) {
+	if !d.strict {
+		return
+	}
+
+	keys, ok := unusedKeys(decoder)
+	if !ok {
+		return
+	}
+
+	for _, key := range keys {
+		d.unknownKeyErrors = append(d.unknownKeyErrors, &FieldError{
+			Path: path,
+			Err: &UnknownKeyError{
+				Key:        key,
+				Suggestion: suggestKey(key, fields, d.naming),
+			},
+		})
+	}
+}
+
+// metadataTags are the tags that are not used as keys by decoders,
+// so they are not considered when suggesting keys:
+var metadataTags = map[string]bool{
+	"validate":  true,
+	"transform": true,
+	"secret":    true,
+	"sensitive": true,
+	"file":      true,
+}
+
+// suggestKey returns the tag value or field name closest to
+// the unknown key, ignoring case, or an empty string if none
+// of them is close enough to be a likely typo.
+//
+// On ties the tag values and the names computed by the NamingStrategy
+// are preferred over the field names since they are usually the keys
+// of the source.
+func suggestKey(key string, fields []Field, naming NamingStrategy) string {
+	maxDistance := len(key) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	suggestion := ""
+	bestDistance := maxDistance + 1
+	for _, field := range fields {
+		tagNames := make([]string, 0, len(field.Tags))
+		for tagName := range field.Tags {
+			if !metadataTags[tagName] {
+				tagNames = append(tagNames, tagName)
+			}
+		}
+		sort.Strings(tagNames)
+
+		candidates := make([]string, 0, len(tagNames)+1)
+		for _, tagName := range tagNames {
+			candidates = append(candidates, strings.Split(field.Tags[tagName], ",")[0])
+		}
+		if naming != nil {
+			candidates = append(candidates, naming(field.Name))
+		}
+		candidates = append(candidates, field.Name)
+
+		for _, candidate := range candidates {
+			if candidate == "" || candidate == "-" {
+				continue
+			}
+
+			distance := editDistance(strings.ToLower(key), strings.ToLower(candidate))
+			if distance < bestDistance {
+				suggestion = candidate
+				bestDistance = distance
+			}
+		}
+	}
+
+	return suggestion
+}
+
+// editDistance returns the number of insertions, deletions, substitutions
+// and transpositions of adjacent characters needed for turning a into b,
+// i.e. the optimal string alignment distance, since these are the most
+// common kinds of typos.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(first int, others ...int) int {
+	result := first
+	for _, v := range others {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,193 @@
+package structscanner_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestStrict(t *testing.T) {
+	type Config struct {
+		Address string `map:"address"`
+		Port    int    `map:"port"`
+		DB      struct {
+			User     string `map:"user"`
+			Password string `map:"password"`
+		} `map:"db"`
+		Replicas []struct {
+			Host string `map:"host"`
+		} `map:"replicas"`
+	}
+
+	t.Run("should ignore unused keys by default", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"adress": "fakeAddress",
+			"db":     map[string]interface{}{},
+		}))
+		tt.AssertNoErr(t, err)
+	})
+
+	t.Run("should accept inputs without unused keys", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"address": "fakeAddress",
+			"db": map[string]interface{}{
+				"user": "fakeUser",
+			},
+		}), ss.Strict())
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Address, "fakeAddress")
+		tt.AssertEqual(t, config.DB.User, "fakeUser")
+	})
+
+	t.Run("should report the unused keys of all nesting levels", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"adress":  "fakeAddress",
+			"timeout": 10,
+			"db": map[string]interface{}{
+				"user":      "fakeUser",
+				"pasword":   "fakePassword",
+				"Passwords": "fakePasswords",
+			},
+			"replicas": []interface{}{},
+		}), ss.Strict())
+		tt.AssertErrContains(t, err,
+			`unknown key "adress", did you mean "address"?`,
+			`unknown key "timeout"`,
+			`DB: unknown key "Passwords", did you mean "password"?`,
+			`DB: unknown key "pasword", did you mean "password"?`,
+		)
+
+		var fieldErrs ss.FieldErrors
+		tt.AssertTrue(t, errors.As(err, &fieldErrs), "error %#v should be a FieldErrors", err)
+		tt.AssertEqual(t, len(fieldErrs), 4)
+
+		tt.AssertEqual(t, fieldErrs[0].Path, "DB")
+		var unknownKeyErr *ss.UnknownKeyError
+		tt.AssertTrue(t, errors.As(fieldErrs[0], &unknownKeyErr), "error %#v should be an UnknownKeyError", fieldErrs[0])
+		tt.AssertEqual(t, unknownKeyErr, &ss.UnknownKeyError{
+			Key:        "Passwords",
+			Suggestion: "password",
+		})
+
+		tt.AssertEqual(t, fieldErrs[2].Path, "")
+		tt.AssertTrue(t, errors.As(fieldErrs[2], &unknownKeyErr), "error %#v should be an UnknownKeyError", fieldErrs[2])
+		tt.AssertEqual(t, unknownKeyErr, &ss.UnknownKeyError{
+			Key:        "adress",
+			Suggestion: "address",
+		})
+	})
+
+	t.Run("should work with wrapped decoders", func(t *testing.T) {
+		var config struct {
+			Address string `env:"ADDRESS"`
+			Port    int    `env:"PORT"`
+		}
+		err := ss.Decode(&config, ss.NamedTagDecoder("env", ss.NewStringMapTagDecoder("env", map[string]interface{}{
+			"ADDRESS": "fakeAddress",
+			"PROT":    "8080",
+		})), ss.Strict())
+		tt.AssertErrContains(t, err, `unknown key "PROT", did you mean "PORT"?`)
+	})
+
+	t.Run("should report the unused keys of all the sources of a chain", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, ss.ChainTagDecoder(
+			ss.NewMapTagDecoder("map", map[string]interface{}{
+				"hots": "fakeHost",
+				"port": 8080,
+				"db": map[string]interface{}{
+					"user": "fakeUser",
+				},
+			}),
+			ss.NewMapTagDecoder("map", map[string]interface{}{
+				"address": "fakeDefaultAddress",
+				"port":    80,
+				"db": map[string]interface{}{
+					"user":     "fakeDefaultUser",
+					"pasword":  "fakeDefaultPassword",
+					"password": "fakeDefaultPassword",
+				},
+			}),
+			ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+				return nil, nil
+			}),
+		), ss.Strict())
+		tt.AssertErrContains(t, err,
+			`unknown key "hots"`,
+			`DB: unknown key "pasword", did you mean "password"?`,
+		)
+
+		var fieldErrs ss.FieldErrors
+		tt.AssertTrue(t, errors.As(err, &fieldErrs), "error %#v should be a FieldErrors", err)
+		tt.AssertEqual(t, len(fieldErrs), 2)
+		tt.AssertEqual(t, config.Port, 8080)
+		tt.AssertEqual(t, config.DB.User, "fakeUser")
+	})
+
+	t.Run("should mark keys of lower priority sources without reading their values", func(t *testing.T) {
+		var config struct {
+			Password string `env:"PASSWORD"`
+		}
+
+		opened := 0
+		calls := 0
+		err := ss.Decode(&config, ss.ChainTagDecoder(
+			ss.NewMapTagDecoder("env", map[string]interface{}{
+				"PASSWORD": "fakePassword",
+			}),
+			ss.NewFileTagDecoder("env", ss.NewMapTagDecoder("env", map[string]interface{}{
+				"PASSWORD_FILE": "/run/secrets/password",
+			})).WithFS(openFunc(func(name string) (fs.File, error) {
+				opened++
+				return nil, fs.ErrNotExist
+			})),
+			ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+				calls++
+				return nil, nil
+			}),
+		), ss.Strict())
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Password, "fakePassword")
+		tt.AssertEqual(t, opened, 0)
+		tt.AssertEqual(t, calls, 0)
+	})
+
+	t.Run("should accept chains without unused keys", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, ss.ChainTagDecoder(
+			ss.NewMapTagDecoder("map", map[string]interface{}{
+				"port": 8080,
+			}),
+			ss.NewMapTagDecoder("map", map[string]interface{}{
+				"address": "fakeDefaultAddress",
+				"port":    80,
+			}),
+		), ss.Strict())
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Address, "fakeDefaultAddress")
+		tt.AssertEqual(t, config.Port, 8080)
+	})
+
+	t.Run("should not fail for decoders that don't report unused keys", func(t *testing.T) {
+		var config struct {
+			Address string `map:"address"`
+		}
+		err := ss.Decode(&config, ss.FuncTagDecoder(func(field ss.Field) (interface{}, error) {
+			return "fakeAddress", nil
+		}), ss.Strict())
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, config.Address, "fakeAddress")
+	})
+}
+
+type openFunc func(name string) (fs.File, error)
+
+func (f openFunc) Open(name string) (fs.File, error) {
+	return f(name)
+}
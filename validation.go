@@ -0,0 +1,313 @@
+package structscanner
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/vingarcia/structscanner/internal/types"
+)
+
+// ValidationFunc checks if the value of a field satisfies a validation rule.
+//
+// The param argument is the text after the `=` sign of the rule,
+// e.g. "10" for the rule `min=10`, or an empty string if there is none.
+type ValidationFunc func(value reflect.Value, param string) error
+
+// RegisterValidation registers a new rule that can be used on the
+// `validate` tag of any struct, replacing any rule with the same name.
+//
+// The rules are checked by Decode after all the fields of
+// a struct are decoded, e.g.:
+//
+//	type Config struct {
+//		Port    int    `env:"PORT" validate:"min=1,max=65535"`
+//		LogMode string `env:"LOG_MODE" validate:"oneof=json text"`
+//	}
+//
+// Rules are separated by commas, so commas that are part
+// of a param should be escaped, e.g. `validate:"regex=^a\\,b$"`.
+//
+// The rule is registered on the default Scanner, use
+// `Scanner.RegisterValidation()` for registering it on another instance.
+func RegisterValidation(name string, fn ValidationFunc) {
+	defaultScanner.RegisterValidation(name, fn)
+}
+
+// RegisterValidation works like the RegisterValidation
+// function but only for this Scanner instance.
+func (s *Scanner) RegisterValidation(name string, fn ValidationFunc) {
+	s.registryMutex.Lock()
+	defer s.registryMutex.Unlock()
+	s.validationFuncs[name] = fn
+}
+
+// builtinValidationFuncs are the rules available on every new Scanner:
+var builtinValidationFuncs = map[string]ValidationFunc{
+	"min":      validateMin,
+	"max":      validateMax,
+	"len":      validateLen,
+	"oneof":    validateOneOf,
+	"regex":    validateRegex,
+	"nonempty": validateNonEmpty,
+	"url":      validateURL,
+	"email":    validateEmail,
+}
+
+func (s *Scanner) getValidationFunc(name string) (ValidationFunc, bool) {
+	s.registryMutex.RLock()
+	defer s.registryMutex.RUnlock()
+	fn, found := s.validationFuncs[name]
+	return fn, found
+}
+
+type validationRule struct {
+	name  string
+	param string
+}
+
+func parseValidationRules(tag string) ([]validationRule, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	var rules []validationRule
+	for _, rule := range splitUnescaped(tag, ',') {
+		rule = strings.ReplaceAll(rule, `\,`, ",")
+
+		name, param := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("missing rule name on: %q", tag)
+		}
+
+		rules = append(rules, validationRule{
+			name:  name,
+			param: param,
+		})
+	}
+
+	return rules, nil
+}
+
+func (d *decodeState) validateStruct(path string, structValue reflect.Value, fields []Field) {
+	for _, field := range fields {
+		fieldValue := structValue.Field(field.idx)
+		for _, rule := range field.validations {
+			err := d.validateField(fieldValue, rule)
+			if err != nil {
+				d.validationErrors = append(d.validationErrors,
+					newFieldError(joinPath(path, field.Name), field, fieldValue.Interface(), err),
+				)
+			}
+		}
+	}
+}
+
+func (d *decodeState) validateField(value reflect.Value, rule validationRule) error {
+	fn, found := d.scanner.getValidationFunc(rule.name)
+	if !found {
+		return fmt.Errorf("unknown validation rule: %q", rule.name)
+	}
+
+	// Rules apply to the wrapped value of Secret fields,
+	// nil *Secret fields are handled as any nil pointer:
+	if value.Kind() == reflect.Ptr && !value.IsNil() && isSecretType(value.Type()) {
+		value = value.Elem()
+	}
+	if secret, ok := value.Interface().(secretGetter); ok && value.Kind() != reflect.Ptr {
+		value = secret.secretValue()
+	}
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			// Only the nonempty rule applies to nil pointers,
+			// this way optional fields can still have validations:
+			if rule.name == "nonempty" {
+				return fmt.Errorf("must not be empty")
+			}
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	return fn(value, rule.param)
+}
+
+func validateMin(value reflect.Value, param string) error {
+	cmp, err := compareWithParam(value, param)
+	if err != nil {
+		return err
+	}
+
+	if cmp < 0 {
+		if hasLength(value) {
+			return fmt.Errorf("length must be at least %s", param)
+		}
+		return fmt.Errorf("must be at least %s", param)
+	}
+	return nil
+}
+
+func validateMax(value reflect.Value, param string) error {
+	cmp, err := compareWithParam(value, param)
+	if err != nil {
+		return err
+	}
+
+	if cmp > 0 {
+		if hasLength(value) {
+			return fmt.Errorf("length must be at most %s", param)
+		}
+		return fmt.Errorf("must be at most %s", param)
+	}
+	return nil
+}
+
+func validateLen(value reflect.Value, param string) error {
+	if !hasLength(value) {
+		return fmt.Errorf("the len rule can't be used on values of type %v", value.Type())
+	}
+
+	cmp, err := compareWithParam(value, param)
+	if err != nil {
+		return err
+	}
+
+	if cmp != 0 {
+		return fmt.Errorf("length must be exactly %s", param)
+	}
+	return nil
+}
+
+func validateOneOf(value reflect.Value, param string) error {
+	options := strings.Fields(param)
+
+	str := fmt.Sprint(value.Interface())
+	for _, option := range options {
+		if str == option {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("must be one of %v", options)
+}
+
+var regexCache sync.Map
+
+func validateRegex(value reflect.Value, param string) error {
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("the regex rule can only be used on strings, but got: %v", value.Type())
+	}
+
+	re, found := regexCache.Load(param)
+	if !found {
+		compiled, err := regexp.Compile(param)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %w", param, err)
+		}
+		re, _ = regexCache.LoadOrStore(param, compiled)
+	}
+
+	if !re.(*regexp.Regexp).MatchString(value.String()) {
+		return fmt.Errorf("must match the regex: %s", param)
+	}
+	return nil
+}
+
+func validateNonEmpty(value reflect.Value, _ string) error {
+	if hasLength(value) {
+		if value.Len() == 0 {
+			return fmt.Errorf("must not be empty")
+		}
+		return nil
+	}
+
+	if value.IsZero() {
+		return fmt.Errorf("must not be empty")
+	}
+	return nil
+}
+
+func validateURL(value reflect.Value, _ string) error {
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("the url rule can only be used on strings, but got: %v", value.Type())
+	}
+
+	u, err := url.ParseRequestURI(value.String())
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("must be a valid URL")
+	}
+	return nil
+}
+
+func validateEmail(value reflect.Value, _ string) error {
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("the email rule can only be used on strings, but got: %v", value.Type())
+	}
+
+	addr, err := mail.ParseAddress(value.String())
+	if err != nil || addr.Address != value.String() {
+		return fmt.Errorf("must be a valid email address")
+	}
+	return nil
+}
+
+func hasLength(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// compareWithParam returns -1, 0 or 1 if the value (or its
+// length) is smaller, equal or bigger than the param.
+func compareWithParam(value reflect.Value, param string) (int, error) {
+	if hasLength(value) {
+		length := value.Len()
+		if value.Kind() == reflect.String {
+			length = utf8.RuneCountInString(value.String())
+		}
+		value = reflect.ValueOf(length)
+	}
+
+	if !isNumericKind(value.Kind()) {
+		return 0, fmt.Errorf("can't compare values of type %v with %q", value.Type(), param)
+	}
+
+	// Parsing the param as the same type of the value
+	// allows params like `min=1s` for time.Duration fields:
+	parsedParam, err := types.StringToType(value.Type(), param)
+	if err != nil {
+		return 0, fmt.Errorf("invalid param %q for values of type %v: %w", param, value.Type(), err)
+	}
+
+	a, b := toFloat64(value), toFloat64(parsedParam)
+	switch {
+	case a < b:
+		return -1, nil
+	case a > b:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func toFloat64(value reflect.Value) float64 {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	}
+	return value.Float()
+}
@@ -0,0 +1,246 @@
+package structscanner_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ss "github.com/vingarcia/structscanner"
+	tt "github.com/vingarcia/structscanner/internal/testtools"
+)
+
+func TestValidation(t *testing.T) {
+	type Config struct {
+		Port     int           `map:"port" validate:"min=1,max=65535"`
+		Timeout  time.Duration `map:"timeout" validate:"min=1s"`
+		Name     string        `map:"name" validate:"min=3,max=10"`
+		Code     string        `map:"code" validate:"len=4,regex=^[A-Z]+\\,?[0-9]*$"`
+		LogMode  string        `map:"log_mode" validate:"oneof=json text"`
+		Hosts    []string      `map:"hosts" validate:"nonempty"`
+		Endpoint string        `map:"endpoint" validate:"url"`
+		Contact  string        `map:"contact" validate:"email"`
+		Optional *int          `map:"optional" validate:"min=10"`
+		DB       struct {
+			User string `map:"user" validate:"nonempty"`
+		} `map:"db"`
+	}
+
+	validInput := func() map[string]interface{} {
+		return map[string]interface{}{
+			"port":     8080,
+			"timeout":  5 * time.Second,
+			"name":     "fakeName",
+			"code":     "AB,1",
+			"log_mode": "json",
+			"hosts":    []string{"localhost"},
+			"endpoint": "https://example.com/path",
+			"contact":  "fake@example.com",
+			"db": map[string]interface{}{
+				"user": "fakeUser",
+			},
+		}
+	}
+
+	t.Run("should accept valid values", func(t *testing.T) {
+		var config Config
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", validInput()))
+		tt.AssertNoErr(t, err)
+	})
+
+	t.Run("should report each invalid field", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			key                string
+			value              interface{}
+			expectErrToContain []string
+		}{
+			{
+				desc:               "number smaller than min",
+				key:                "port",
+				value:              0,
+				expectErrToContain: []string{"Port", "must be at least 1"},
+			},
+			{
+				desc:               "number bigger than max",
+				key:                "port",
+				value:              70000,
+				expectErrToContain: []string{"Port", "must be at most 65535"},
+			},
+			{
+				desc:               "duration smaller than min",
+				key:                "timeout",
+				value:              time.Millisecond,
+				expectErrToContain: []string{"Timeout", "must be at least 1s"},
+			},
+			{
+				desc:               "string too short",
+				key:                "name",
+				value:              "ab",
+				expectErrToContain: []string{"Name", "length must be at least 3"},
+			},
+			{
+				desc:               "string too long",
+				key:                "name",
+				value:              "fakeVeryLongName",
+				expectErrToContain: []string{"Name", "length must be at most 10"},
+			},
+			{
+				desc:               "wrong length",
+				key:                "code",
+				value:              "AB",
+				expectErrToContain: []string{"Code", "length must be exactly 4"},
+			},
+			{
+				desc:               "regex mismatch",
+				key:                "code",
+				value:              "ab12",
+				expectErrToContain: []string{"Code", "must match the regex", "^[A-Z]+,?[0-9]*$"},
+			},
+			{
+				desc:               "not one of the options",
+				key:                "log_mode",
+				value:              "xml",
+				expectErrToContain: []string{"LogMode", "must be one of [json text]"},
+			},
+			{
+				desc:               "empty slice",
+				key:                "hosts",
+				value:              []string{},
+				expectErrToContain: []string{"Hosts", "must not be empty"},
+			},
+			{
+				desc:               "invalid url",
+				key:                "endpoint",
+				value:              "not a url",
+				expectErrToContain: []string{"Endpoint", "must be a valid URL"},
+			},
+			{
+				desc:               "invalid email",
+				key:                "contact",
+				value:              "Fake Name <fake@example.com>",
+				expectErrToContain: []string{"Contact", "must be a valid email address"},
+			},
+			{
+				desc:               "pointer smaller than min",
+				key:                "optional",
+				value:              5,
+				expectErrToContain: []string{"Optional", "must be at least 10"},
+			},
+			{
+				desc:               "nested struct field",
+				key:                "db",
+				value:              map[string]interface{}{},
+				expectErrToContain: []string{"DB.User", "must not be empty"},
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				input := validInput()
+				input[test.key] = test.value
+
+				var config Config
+				err := ss.Decode(&config, ss.NewMapTagDecoder("map", input))
+				tt.AssertErrContains(t, err, test.expectErrToContain...)
+
+				var fieldErr *ss.FieldError
+				tt.AssertTrue(t, errors.As(err, &fieldErr), "error %#v should be a FieldError", err)
+			})
+		}
+	})
+
+	t.Run("should validate nested structs missing on the source", func(t *testing.T) {
+		var user struct {
+			Name  string `map:"name"`
+			Inner struct {
+				X     int    `map:"x" validate:"min=1"`
+				Items []int  `map:"items" validate:"nonempty"`
+				Skip  string `map:"skip"`
+			} `map:"inner"`
+			Optional *struct {
+				X int `map:"x" validate:"min=1"`
+			} `map:"optional"`
+		}
+		err := ss.Decode(&user, ss.ChainTagDecoder(
+			ss.NewMapTagDecoder("map", map[string]interface{}{
+				"name": "fakeName",
+			}),
+		))
+		tt.AssertErrContains(t, err, "Inner.X", "must be at least 1", "Inner.Items", "must not be empty")
+		tt.AssertEqual(t, user.Optional == nil, true)
+	})
+
+	t.Run("should report all invalid fields at once", func(t *testing.T) {
+		input := validInput()
+		input["port"] = 0
+		input["name"] = "ab"
+
+		var config Config
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", input))
+
+		var fieldErrs ss.FieldErrors
+		tt.AssertTrue(t, errors.As(err, &fieldErrs), "error %#v should be a FieldErrors", err)
+		tt.AssertEqual(t, len(fieldErrs), 2)
+		tt.AssertEqual(t, fieldErrs[0].Path, "Port")
+		tt.AssertEqual(t, fieldErrs[0].Value, 0)
+		tt.AssertEqual(t, fieldErrs[1].Path, "Name")
+		tt.AssertEqual(t, fieldErrs[1].Value, "ab")
+
+		// Is and As work even on Go versions that ignore Unwrap() []error:
+		var fieldErr *ss.FieldError
+		tt.AssertTrue(t, fieldErrs.As(&fieldErr), "FieldErrors should match a FieldError")
+		tt.AssertEqual(t, fieldErr.Path, "Port")
+		tt.AssertTrue(t, fieldErrs.Is(fieldErrs[1]), "FieldErrors should match any of its errors")
+	})
+
+	t.Run("should validate the wrapped value of secrets without leaking it", func(t *testing.T) {
+		var config struct {
+			Password ss.Secret[string] `map:"password" validate:"min=8"`
+		}
+		err := ss.Decode(&config, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"password": "short",
+		}))
+		tt.AssertErrContains(t, err, "Password")
+		tt.AssertEqual(t, strings.Contains(err.Error(), "short"), false, err.Error())
+	})
+
+	t.Run("should support user registered rules", func(t *testing.T) {
+		ss.RegisterValidation("even", func(value reflect.Value, param string) error {
+			if value.Int()%2 != 0 {
+				return fmt.Errorf("must be even")
+			}
+			return nil
+		})
+
+		var output struct {
+			Attr1 int `map:"attr1" validate:"even"`
+		}
+		err := ss.Decode(&output, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"attr1": 3,
+		}))
+		tt.AssertErrContains(t, err, "Attr1", "must be even")
+
+		err = ss.Decode(&output, ss.NewMapTagDecoder("map", map[string]interface{}{
+			"attr1": 4,
+		}))
+		tt.AssertNoErr(t, err)
+	})
+
+	t.Run("should report unknown rules", func(t *testing.T) {
+		var output struct {
+			Attr1 int `map:"attr1" validate:"notARule"`
+		}
+		err := ss.Decode(&output, ss.NewMapTagDecoder("map", map[string]interface{}{}))
+		tt.AssertErrContains(t, err, "Attr1", "unknown validation rule", "notARule")
+	})
+
+	t.Run("should report malformed validate tags", func(t *testing.T) {
+		var output struct {
+			Attr1 int `map:"attr1" validate:"min=1,,max=2"`
+		}
+		err := ss.Decode(&output, ss.NewMapTagDecoder("map", map[string]interface{}{}))
+		tt.AssertErrContains(t, err, "Attr1", "invalid validate tag", "missing rule name")
+	})
+}
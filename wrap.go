@@ -0,0 +1,50 @@
+package structscanner
+
+import "reflect"
+
+// The helpers below are shared by the decoders that wrap other
+// decoders, like the FileTagDecoder and the InterpolateTagDecoder.
+
+// hasNestedValues returns true for fields whose values are nested
+// decoders or slices of items, which the wrapping decoders don't
+// handle themselves, they only wrap the nested decoders.
+func hasNestedValues(info Field) bool {
+	isNestedStruct := isStructOrStructPtr(info.Type) && !isSecretType(info.Type) && !info.hasConverter
+	isSliceOfItems := info.Kind == reflect.Slice && info.Type != bytesType
+	return isNestedStruct || isSliceOfItems
+}
+
+// wrapNested replaces the nested decoders of the value, including
+// the ones on slices of items, with the result of the wrap function.
+func wrapNested(value interface{}, wrap func(nested TagDecoder) TagDecoder) interface{} {
+	if nestedDecoder, ok := value.(TagDecoder); ok {
+		return wrap(nestedDecoder)
+	}
+
+	if items, ok := value.([]interface{}); ok {
+		wrappedItems := make([]interface{}, len(items))
+		for i, item := range items {
+			wrappedItems[i] = wrapNested(item, wrap)
+		}
+		return wrappedItems
+	}
+
+	return value
+}
+
+// stringKeyField returns a copy of the field with the given key as if
+// it was a string field, so decoders that parse their values by the
+// type of the field don't try to parse paths or `${...}` expressions.
+func stringKeyField(info Field, tagName string, key string) Field {
+	keyTags := make(map[string]string, len(info.Tags))
+	for name, value := range info.Tags {
+		keyTags[name] = value
+	}
+	keyTags[tagName] = key
+
+	keyField := info
+	keyField.Tags = keyTags
+	keyField.Kind = reflect.String
+	keyField.Type = stringType
+	return keyField
+}